@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ilhamtubagus/goenv"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// runCommand executes a command with the variables of the dotenv files added to its environment.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	var files filesFlag
+	flags := newFlagSet("run", stderr)
+	flags.Var(&files, "f", "dotenv `file` to read, can be repeated (default .env)")
+	override := flags.Bool("override", false, "let the files override variables already set in the environment")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "goenv run: missing command")
+		return exitUsage
+	}
+
+	if len(files) == 0 {
+		files = filesFlag{".env"}
+	}
+	layers := make([]goenv.Source, 0, len(files)+1)
+	for _, filename := range files {
+		source, err := goenv.ReadDotenvFile(filename)
+		if err != nil {
+			fmt.Fprintf(stderr, "goenv run: %v\n", err)
+			return exitFailure
+		}
+		layers = append(layers, source)
+	}
+	if *override {
+		layers = append([]goenv.Source{goenv.OSSource{}}, layers...)
+	} else {
+		layers = append(layers, goenv.OSSource{})
+	}
+	source := goenv.NewLayeredSource(goenv.LastWins, layers...)
+
+	env := make([]string, 0, len(source.Keys()))
+	for _, key := range source.Keys() {
+		value, _ := source.Lookup(key)
+		env = append(env, key+"="+value)
+	}
+
+	cmd := exec.Command(flags.Arg(0), flags.Args()[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(stderr, "goenv run: %v\n", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// checkCommand validates the syntax of dotenv files.
+func checkCommand(args []string, stdout, stderr io.Writer) int {
+	var files filesFlag
+	flags := newFlagSet("check", stderr)
+	flags.Var(&files, "f", "dotenv `file` to check, can be repeated")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	files = append(files, flags.Args()...)
+	if len(files) == 0 {
+		files = filesFlag{".env"}
+	}
+
+	status := exitOK
+	for _, filename := range files {
+		if _, err := goenv.ReadDotenvFile(filename); err != nil {
+			fmt.Fprintln(stderr, err)
+			status = exitFailure
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: ok\n", filename)
+	}
+
+	return status
+}
+
+// diffCommand reports the variables missing from a dotenv file or from its example.
+func diffCommand(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("diff", stderr)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "goenv diff: expected a file and an example file")
+		return exitUsage
+	}
+
+	filename, exampleName := flags.Arg(0), flags.Arg(1)
+	file, err := goenv.ReadDotenvFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "goenv diff: %v\n", err)
+		return exitFailure
+	}
+	example, err := goenv.ReadDotenvFile(exampleName)
+	if err != nil {
+		fmt.Fprintf(stderr, "goenv diff: %v\n", err)
+		return exitFailure
+	}
+
+	status := exitOK
+	for _, key := range example.Keys() {
+		if _, ok := file[key]; !ok {
+			fmt.Fprintf(stdout, "- %s (missing from %s)\n", key, filename)
+			status = exitFailure
+		}
+	}
+	for _, key := range file.Keys() {
+		if _, ok := example[key]; !ok {
+			fmt.Fprintf(stdout, "+ %s (missing from %s)\n", key, exampleName)
+			status = exitFailure
+		}
+	}
+
+	return status
+}
+
+// fmtCommand normalises dotenv files.
+func fmtCommand(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("fmt", stderr)
+	write := flags.Bool("w", false, "write the result to the files instead of the standard output")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "goenv fmt: missing file")
+		return exitUsage
+	}
+
+	status := exitOK
+	for _, filename := range flags.Args() {
+		if err := formatFile(filename, *write, stdout); err != nil {
+			fmt.Fprintf(stderr, "goenv fmt: %v\n", err)
+			status = exitFailure
+		}
+	}
+
+	return status
+}
+
+func formatFile(filename string, write bool, stdout io.Writer) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := goenv.FormatDotenv(&buf, bytes.NewReader(data)); err != nil {
+		var syntaxErr goenv.DotenvSyntaxError
+		if errors.As(err, &syntaxErr) {
+			syntaxErr.Filename = filename
+			err = syntaxErr
+		}
+		return err
+	}
+
+	if !write {
+		_, err := stdout.Write(buf.Bytes())
+		return err
+	}
+	if bytes.Equal(data, buf.Bytes()) {
+		return nil
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, buf.Bytes(), info.Mode().Perm())
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet("goenv "+name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: goenv %s\n", usages[name])
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
@@ -0,0 +1,92 @@
+// Command goenv works with dotenv files, using the dotenv parser and the source layering of the goenv package.
+//
+// Usage:
+//
+//	goenv run [-f file]... [-override] -- command [args...]
+//	goenv check [-f file]... [file...]
+//	goenv diff file example
+//	goenv fmt [-w] file...
+//
+// The run command executes a command with the variables of the given files, read in order so later files
+// override earlier ones, added to its environment. Variables already set in the environment win unless
+// -override is given. When no file is given, .env is read.
+//
+// The check command validates the syntax of dotenv files, reporting the first error of each file with
+// its position. When no file is given, .env is checked.
+//
+// The diff command reports the variables of example missing from file, and the variables of file missing
+// from example, e.g. goenv diff .env .env.example. It exits with status 1 when the files differ.
+//
+// The fmt command normalises dotenv files, see goenv.FormatDotenv. The result is written to the standard
+// output unless -w is given, in which case the files are rewritten in place.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+var (
+	commands = map[string]func(args []string, stdout, stderr io.Writer) int{
+		"run":   runCommand,
+		"check": checkCommand,
+		"diff":  diffCommand,
+		"fmt":   fmtCommand,
+	}
+
+	usages = map[string]string{
+		"run":   "run [-f file]... [-override] -- command [args...]",
+		"check": "check [-f file]... [file...]",
+		"diff":  "diff file example",
+		"fmt":   "fmt [-w] file...",
+	}
+)
+
+func main() {
+	os.Exit(cli(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func cli(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] != "help" && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+			fmt.Fprintf(stderr, "goenv: unknown command %q\n", args[0])
+		}
+		usage(stderr)
+		return exitUsage
+	}
+
+	return cmd(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	for _, name := range []string{"run", "check", "diff", "fmt"} {
+		fmt.Fprintf(w, "\tgoenv %s\n", usages[name])
+	}
+}
+
+// filesFlag collects the values of a flag which can be repeated, e.g. -f .env -f .env.local.
+type filesFlag []string
+
+func (f *filesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *filesFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
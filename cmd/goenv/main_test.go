@@ -0,0 +1,184 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func TestCli(t *testing.T) {
+	t.Run("Unknown command", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"unknown"}, &stdout, &stderr)
+
+		assert.Equal(t, exitUsage, status)
+		assert.Contains(t, stderr.String(), `unknown command "unknown"`)
+		assert.Contains(t, stderr.String(), "goenv run")
+	})
+
+	t.Run("Missing command", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli(nil, &stdout, &stderr)
+
+		assert.Equal(t, exitUsage, status)
+		assert.Contains(t, stderr.String(), "Usage:")
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	base := writeFile(t, dir, ".env", "GOENV_CLI_A=base\nGOENV_CLI_B=base\n")
+	local := writeFile(t, dir, ".env.local", "GOENV_CLI_B=local\n")
+
+	t.Run("Later files override earlier ones", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-f", base, "-f", local, "--", "sh", "-c", "echo $GOENV_CLI_A $GOENV_CLI_B"}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Equal(t, "base local\n", stdout.String())
+	})
+
+	t.Run("Environment wins", func(t *testing.T) {
+		t.Setenv("GOENV_CLI_A", "env")
+
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-f", base, "--", "sh", "-c", "echo $GOENV_CLI_A"}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Equal(t, "env\n", stdout.String())
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		t.Setenv("GOENV_CLI_A", "env")
+
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-override", "-f", base, "--", "sh", "-c", "echo $GOENV_CLI_A"}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Equal(t, "base\n", stdout.String())
+	})
+
+	t.Run("Exit status is forwarded", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-f", base, "--", "sh", "-c", "exit 3"}, &stdout, &stderr)
+
+		assert.Equal(t, 3, status)
+	})
+
+	t.Run("Missing command", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-f", base}, &stdout, &stderr)
+
+		assert.Equal(t, exitUsage, status)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"run", "-f", filepath.Join(dir, "missing"), "--", "true"}, &stdout, &stderr)
+
+		assert.Equal(t, exitFailure, status)
+	})
+}
+
+func TestCheckCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeFile(t, dir, "valid.env", "A=1\n")
+	invalid := writeFile(t, dir, "invalid.env", "A=1\nB 2\n")
+
+	t.Run("Valid", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"check", "-f", valid}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Equal(t, valid+": ok\n", stdout.String())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"check", valid, invalid}, &stdout, &stderr)
+
+		assert.Equal(t, exitFailure, status)
+		assert.Equal(t, invalid+":2:3: expected '=' after variable name \"B\"\n", stderr.String())
+	})
+}
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	env := writeFile(t, dir, ".env", "A=1\nB=2\nEXTRA=3\n")
+	example := writeFile(t, dir, ".env.example", "A=\nB=\nMISSING=\n")
+
+	t.Run("Differences", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"diff", env, example}, &stdout, &stderr)
+
+		assert.Equal(t, exitFailure, status)
+		assert.Equal(t, "- MISSING (missing from "+env+")\n+ EXTRA (missing from "+example+")\n", stdout.String())
+	})
+
+	t.Run("Same keys", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"diff", env, env}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("Wrong arguments", func(t *testing.T) {
+		var stdout, stderr strings.Builder
+		status := cli([]string{"diff", env}, &stdout, &stderr)
+
+		assert.Equal(t, exitUsage, status)
+	})
+}
+
+func TestFmtCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Standard output", func(t *testing.T) {
+		filename := writeFile(t, dir, "stdout.env", "export A = 1\n\n\nB='two words'\n")
+
+		var stdout, stderr strings.Builder
+		status := cli([]string{"fmt", filename}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, status)
+		assert.Equal(t, "A=1\n\nB=\"two words\"\n", stdout.String())
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		filename := writeFile(t, dir, "write.env", "# comment\nA = 1 # inline\n")
+
+		var stdout, stderr strings.Builder
+		status := cli([]string{"fmt", "-w", filename}, &stdout, &stderr)
+
+		data, _ := os.ReadFile(filename)
+		assert.Equal(t, exitOK, status)
+		assert.Empty(t, stdout.String())
+		assert.Equal(t, "# comment\nA=1 # inline\n", string(data))
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		filename := writeFile(t, dir, "invalid.env", "A 1\n")
+
+		var stdout, stderr strings.Builder
+		status := cli([]string{"fmt", "-w", filename}, &stdout, &stderr)
+
+		assert.Equal(t, exitFailure, status)
+		assert.Contains(t, stderr.String(), filename+":1:3:")
+	})
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ilhamtubagus/goenv/internal/envtypes"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const goenvPath = "github.com/ilhamtubagus/goenv"
+
+// generator writes the UnmarshalEnv methods of the struct types of a package.
+type generator struct {
+	pkg  *types.Package
+	body bytes.Buffer
+
+	// imports maps the paths of the packages used by the generated code to their names.
+	imports map[string]string
+}
+
+// generate returns the source of a file declaring the UnmarshalEnv methods of the given types of pkg.
+func generate(pkg *types.Package, typeNames []string) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		imports: map[string]string{goenvPath: "goenv"},
+	}
+
+	for _, typeName := range typeNames {
+		obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", typeName, pkg.Path())
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", typeName)
+		}
+		if err := g.writeMethod(typeName, st); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by goenvgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name())
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(g.body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+func (g *generator) writeMethod(typeName string, st *types.Struct) error {
+	receiver := strings.ToLower(typeName[:1])
+
+	g.printf("// UnmarshalEnv populates the fields of %s from the variables of src, like goenv.Unmarshal does\n", typeName)
+	g.printf("// by reflection.\n")
+	g.printf("func (%s *%s) UnmarshalEnv(src goenv.Source) error {\n", receiver, typeName)
+	if err := g.writeStruct(st, receiver, "", ""); err != nil {
+		return err
+	}
+	g.writeValidate(g.pkg.Scope().Lookup(typeName).Type(), receiver, "")
+	g.printf("return nil\n}\n\n")
+
+	return nil
+}
+
+// writeStruct writes the code decoding the fields of a struct, mirroring how goenv decodes it by reflection.
+// The struct is accessed through the expression expr, a pointer for the decoded struct itself.
+func (g *generator) writeStruct(st *types.Struct, expr, path, prefix string) error {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		if tag.Get(envtypes.TagName) == envtypes.IgnoredTag || field.Name() == "_" {
+			continue
+		}
+		if !field.Exported() && field.Pkg() != g.pkg && tag.Get(envtypes.TagName) != "" {
+			return fmt.Errorf("field %s of package %s is not accessible", field.Name(), field.Pkg().Path())
+		}
+
+		if err := g.writeField(field, tag, expr+"."+field.Name(), envtypes.JoinPath(path, field.Name()), prefix); err != nil {
+			return err
+		}
+	}
+
+	// conditional requirements are checked by goenv once every field is decoded
+	if hasConditions(st) {
+		ptr := "&" + expr
+		if path == "" {
+			ptr = expr
+		}
+		g.printf("if err := goenv.CheckConditions(src, %s, %q, %q); err != nil {\nreturn err\n}\n", ptr, path, prefix)
+	}
+
+	return nil
+}
+
+// hasConditions reports whether a field of st has a conditional requirement.
+func hasConditions(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		for _, tagName := range envtypes.ConditionTagNames {
+			if reflect.StructTag(st.Tag(i)).Get(tagName) != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (g *generator) writeField(field *types.Var, tag reflect.StructTag, expr, path, prefix string) error {
+	typ := field.Type()
+	if st, ok := typ.Underlying().(*types.Struct); ok && !envtypes.HasParser(typ) {
+		if err := g.writeStruct(st, expr, path, prefix+tag.Get(envtypes.PrefixTagName)); err != nil {
+			return err
+		}
+		g.writeValidate(typ, expr, path)
+		return nil
+	}
+	// pointers to structs are only allocated when one of their variables is set, which is left to goenv
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok && !envtypes.HasParser(typ) && isStruct(ptr.Elem()) {
+		g.writeFallback(expr, path, tag, prefix)
+		return nil
+	}
+
+	envName, options, _ := strings.Cut(tag.Get(envtypes.TagName), ",")
+	if envName == "" {
+		return nil
+	}
+	// validation rules, files and redaction are left to goenv
+	if tag.Get(envtypes.ValidateTagName) != "" || hasOption(options, envtypes.FileOption) ||
+		hasOption(options, envtypes.SensitiveOption) {
+		g.writeFallback(expr, path, tag, prefix)
+		return nil
+	}
+	lookup := fmt.Sprintf("goenv.LookupValue(src, %q, %q, %q, %t)",
+		path, prefix+envName, tag.Get(envtypes.DefaultTagName), hasOption(options, envtypes.RequiredOption))
+
+	if !envtypes.HasParser(typ) {
+		switch u := typ.Underlying().(type) {
+		case *types.Pointer:
+			if !g.canParse(u.Elem()) {
+				break
+			}
+			g.printf("if value, ok, err := %s; err != nil {\nreturn err\n} else if ok {\n", lookup)
+			g.writeParse(u.Elem(), "value", strconv.Quote(path), prefix+envName)
+			g.printf("%s = &parsedValue\n}\n", expr)
+			return nil
+		case *types.Slice:
+			if !g.canParse(u.Elem()) {
+				break
+			}
+			separator := tag.Get(envtypes.SeparatorTagName)
+			if separator == "" {
+				separator = envtypes.DefaultSeparator
+			}
+			g.imports["strings"] = "strings"
+			g.printf("if value, ok, err := %s; err != nil {\nreturn err\n} else if ok {\n", lookup)
+			g.printf("parts := strings.Split(value, %q)\n", separator)
+			g.printf("values := make(%s, 0, len(parts))\n", types.TypeString(typ, g.qualifier))
+			if canFail(u.Elem()) {
+				g.imports["strconv"] = "strconv"
+				g.printf("for index, part := range parts {\n")
+			} else {
+				g.printf("for _, part := range parts {\n")
+			}
+			g.writeParse(u.Elem(), "part", strconv.Quote(path+"[")+" + strconv.Itoa(index) + \"]\"", prefix+envName)
+			g.printf("values = append(values, parsedValue)\n}\n")
+			g.printf("%s = values\n}\n", expr)
+			return nil
+		case *types.Map:
+			g.writeFallback(expr, path, tag, prefix)
+			return nil
+		}
+	}
+
+	if !g.canParse(typ) {
+		g.writeFallback(expr, path, tag, prefix)
+		return nil
+	}
+	g.printf("if value, ok, err := %s; err != nil {\nreturn err\n} else if ok {\n", lookup)
+	g.writeParse(typ, "value", strconv.Quote(path), prefix+envName)
+	g.printf("%s = parsedValue\n}\n", expr)
+
+	return nil
+}
+
+// canParse reports whether values of typ are parsed by the generated code itself, rather than by goenv.
+func (g *generator) canParse(typ types.Type) bool {
+	if envtypes.HasBuiltinParser(typ) {
+		return false
+	}
+	if _, ok := typ.Underlying().(*types.Pointer); ok {
+		return false
+	}
+
+	switch envtypes.UnmarshalerMethod(typ) {
+	case "UnmarshalEnvValue", "UnmarshalText":
+		return true
+	case "":
+		return envtypes.IsParsedByKind(typ)
+	default:
+		return false
+	}
+}
+
+// canFail reports whether parsing a value of typ can fail, which is the case of every type but strings.
+func canFail(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+
+	return !ok || basic.Info()&types.IsString == 0 || envtypes.UnmarshalerMethod(typ) != ""
+}
+
+// writeParse writes the code parsing the string held by the variable input into a variable named parsedValue,
+// returning a goenv.ParseError on failure. The dotted path of the field is given by the expression pathExpr.
+func (g *generator) writeParse(typ types.Type, input, pathExpr, envName string) {
+	typeName := types.TypeString(typ, g.qualifier)
+	parseError := fmt.Sprintf("goenv.NewParseError(err, %s, %q, %s, *new(%s))", pathExpr, envName, input, typeName)
+
+	switch envtypes.UnmarshalerMethod(typ) {
+	case "UnmarshalEnvValue":
+		g.printf("var parsedValue %s\n", typeName)
+		g.printf("if err := parsedValue.UnmarshalEnvValue(%s); err != nil {\nreturn %s\n}\n", input, parseError)
+		return
+	case "UnmarshalText":
+		g.printf("var parsedValue %s\n", typeName)
+		g.printf("if err := parsedValue.UnmarshalText([]byte(%s)); err != nil {\nreturn %s\n}\n", input, parseError)
+		return
+	}
+
+	basic := typ.Underlying().(*types.Basic)
+	if basic.Info()&types.IsString != 0 {
+		g.printf("parsedValue := %s\n", convert(typeName, "string", input))
+		return
+	}
+
+	var parse, parsedType string
+	switch basic.Kind() {
+	case types.Bool:
+		parse, parsedType = fmt.Sprintf("strconv.ParseBool(%s)", input), "bool"
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		parse, parsedType = fmt.Sprintf("strconv.ParseInt(%s, 10, %d)", input, bitSize(basic.Kind())), "int64"
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		parse, parsedType = fmt.Sprintf("strconv.ParseUint(%s, 10, %d)", input, bitSize(basic.Kind())), "uint64"
+	case types.Float32, types.Float64:
+		parse, parsedType = fmt.Sprintf("strconv.ParseFloat(%s, %d)", input, bitSize(basic.Kind())), "float64"
+	}
+	g.imports["strconv"] = "strconv"
+	g.printf("parsed, err := %s\nif err != nil {\nreturn %s\n}\n", parse, parseError)
+	g.printf("parsedValue := %s\n", convert(typeName, parsedType, "parsed"))
+}
+
+// bitSize returns the size with which goenv parses numbers of the given kind. Like goenv, int and uint
+// values are parsed on 32 bits.
+func bitSize(kind types.BasicKind) int {
+	switch kind {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	default:
+		return 32
+	}
+}
+
+func convert(typeName, valueType, value string) string {
+	if typeName == valueType {
+		return value
+	}
+
+	return typeName + "(" + value + ")"
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+
+	return pkg.Name()
+}
+
+// writeValidate writes the call to the Validate method of a decoded struct of type typ, when it has one,
+// wrapping its error in a goenv.ParseError like goenv does.
+func (g *generator) writeValidate(typ types.Type, expr, path string) {
+	if !envtypes.HasValidateMethod(typ) {
+		return
+	}
+	g.printf("if err := %s.Validate(); err != nil {\nreturn goenv.NewParseError(err, %q, \"\", \"\", *new(%s))\n}\n",
+		expr, path, types.TypeString(typ, g.qualifier))
+}
+
+// writeFallback writes the code decoding a field by reflection, through goenv.UnmarshalField.
+func (g *generator) writeFallback(expr, path string, tag reflect.StructTag, prefix string) {
+	g.printf("if err := goenv.UnmarshalField(src, &%s, %q, %s, %q); err != nil {\nreturn err\n}\n", expr, path, quoteTag(tag), prefix)
+}
+
+// quoteTag quotes a tag as a raw string literal, as tags are usually written, when possible.
+func quoteTag(tag reflect.StructTag) string {
+	if strings.Contains(string(tag), "`") {
+		return strconv.Quote(string(tag))
+	}
+
+	return "`" + string(tag) + "`"
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isStruct(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
@@ -0,0 +1,95 @@
+// Command goenvgen generates UnmarshalEnv methods decoding structs from environment variables without
+// reflection. The generated methods implement goenv.StructUnmarshaler, so goenv.Unmarshal calls them
+// instead of decoding the structs by reflection.
+//
+// Usage:
+//
+//	goenvgen -type Config[,Other...] [-output file] [package]
+//
+// It is meant to be run by go generate, from a comment in the package declaring the types:
+//
+//	//go:generate go run github.com/ilhamtubagus/goenv/cmd/goenvgen -type Config
+//
+// The generated code honours the same tags as goenv.Unmarshal with its default options. Strings, booleans,
+// numbers, types implementing goenv.EnvUnmarshaler or encoding.TextUnmarshaler, slices and pointers of those
+// and nested structs are decoded by the generated code itself; other fields, like maps, times, secrets or pointers
+// to structs, as well as fields with validation rules or the file or sensitive options, are decoded by
+// goenv.UnmarshalField.
+//
+// The output is written to <type>_env.go in the directory of the package, where <type> is the lower-cased
+// name of the first type, unless -output is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golang.org/x/tools/go/packages"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_env.go")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: goenvgen -type Config[,Other...] [-output file] [package]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pattern := "."
+	if flag.NArg() == 1 {
+		pattern = flag.Arg(0)
+	}
+	if err := run(pattern, strings.Split(*typeNames, ","), *output); err != nil {
+		fmt.Fprintf(os.Stderr, "goenvgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pattern string, typeNames []string, output string) error {
+	pkg, err := loadPackage(pattern)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg.Types, typeNames)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		dir := "."
+		if len(pkg.GoFiles) > 0 {
+			dir = filepath.Dir(pkg.GoFiles[0])
+		}
+		output = filepath.Join(dir, strings.ToLower(typeNames[0])+"_env.go")
+	}
+
+	return os.WriteFile(output, src, 0o644)
+}
+
+func loadPackage(pattern string) (*packages.Package, error) {
+	// dependencies are type-checked from source, so the export data of the toolchain does not matter
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(config, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages found for %s, expected one", len(pkgs), pattern)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, pkgs[0].Errors[0]
+	}
+
+	return pkgs[0], nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("Matches the generated file of gentest", func(t *testing.T) {
+		pkg, err := loadPackage("../../internal/gentest")
+		assert.Nil(t, err)
+
+		src, err := generate(pkg.Types, []string{"Config"})
+		assert.Nil(t, err)
+
+		expected, _ := os.ReadFile("../../internal/gentest/config_env.go")
+		assert.Equal(t, string(expected), string(src))
+	})
+
+	t.Run("Unknown type", func(t *testing.T) {
+		pkg, err := loadPackage("../../internal/gentest")
+		assert.Nil(t, err)
+
+		_, err = generate(pkg.Types, []string{"Missing"})
+		assert.EqualError(t, err, "type Missing not found in package github.com/ilhamtubagus/goenv/internal/gentest")
+
+		_, err = generate(pkg.Types, []string{"Level"})
+		assert.EqualError(t, err, "type Level is not a struct")
+	})
+}
@@ -0,0 +1,16 @@
+// Command goenvlint reports mistakes in the tags of structs passed to goenv.Unmarshal, see package envlint.
+//
+// It can be run standalone or as a vet tool:
+//
+//	goenvlint ./...
+//	go vet -vettool=$(which goenvlint) ./...
+package main
+
+import (
+	"github.com/ilhamtubagus/goenv/envlint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(envlint.Analyzer)
+}
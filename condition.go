@@ -0,0 +1,187 @@
+package goenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type conditionKind int
+
+const (
+	// requiredIfKind requires a field when another one has a given value, e.g. `requiredIf:"AuthMode=oidc"`.
+	requiredIfKind conditionKind = iota
+
+	// requiredWithKind requires a field when another one is set, e.g. `requiredWith:"TLSCert"`.
+	requiredWithKind
+
+	// requiredWithoutKind requires a field when another one is not set, e.g. `requiredWithout:"Password"`.
+	requiredWithoutKind
+
+	// excludedWithKind forbids setting a field when another one is set, e.g. `excludedWith:"Token"`.
+	excludedWithKind
+)
+
+// condition is a compiled conditional requirement of a field.
+type condition struct {
+	kind conditionKind
+
+	// ref is the index of the referenced sibling field in the plan of the struct, or -1 when the condition
+	// references a variable by its name, held by envName.
+	ref     int
+	envName string
+
+	// value is the value of the referenced field or variable triggering a requiredIf condition.
+	value string
+}
+
+// compileConditions compiles the conditional requirements of the fields of a struct plan. The names used
+// in the tags are looked up among the decoded fields of the struct, and are otherwise taken as variable names.
+func compileConditions(plan *structPlan, options Options) {
+	tagNames := []string{
+		requiredIfKind:      options.RequiredIfTagName,
+		requiredWithKind:    options.RequiredWithTagName,
+		requiredWithoutKind: options.RequiredWithoutTagName,
+		excludedWithKind:    options.ExcludedWithTagName,
+	}
+
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		if field.nested != nil {
+			continue
+		}
+		tag := plan.typ.Field(field.index).Tag
+		for kind, tagName := range tagNames {
+			for _, name := range strings.Fields(tag.Get(tagName)) {
+				c := condition{kind: conditionKind(kind)}
+				if c.kind == requiredIfKind {
+					var found bool
+					if name, c.value, found = strings.Cut(name, "="); !found {
+						if field.err == nil {
+							field.err = InvalidRuleError{
+								Field: field.path,
+								Rule:  name,
+								Err:   fmt.Errorf("%s condition must be written NAME=VALUE", tagName),
+							}
+						}
+						continue
+					}
+				}
+				c.ref, c.envName = resolveReference(plan, name)
+				field.conditions = append(field.conditions, c)
+			}
+		}
+	}
+}
+
+// resolveReference returns the index and variable name of the decoded field of a struct plan with the given
+// name, or -1 and the name itself when the struct has no such field.
+func resolveReference(plan *structPlan, name string) (int, string) {
+	for i, field := range plan.fields {
+		if field.nested == nil && plan.typ.Field(field.index).Name == name {
+			return i, field.envName
+		}
+	}
+
+	return -1, name
+}
+
+// checkConditions checks the conditional requirements of the fields of a populated struct, returning an
+// error for every field breaking one of them.
+func checkConditions(value reflect.Value, plan *structPlan, options Options) []error {
+	var errs []error
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		for _, c := range field.conditions {
+			if err := c.check(value, plan, field, options); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+func (c condition) check(value reflect.Value, plan *structPlan, field *fieldPlan, options Options) error {
+	refValue, refSet := c.lookup(value, plan, options)
+
+	switch c.kind {
+	case requiredIfKind:
+		if !refSet || refValue != c.value {
+			return nil
+		}
+	case requiredWithKind, excludedWithKind:
+		if !refSet {
+			return nil
+		}
+	case requiredWithoutKind:
+		if refSet {
+			return nil
+		}
+	}
+
+	_, set := lookupField(value, field, options)
+	if c.kind == excludedWithKind {
+		if set {
+			return ExcludedVariableError{Field: field.path, Var: field.envName, Condition: c.description()}
+		}
+		return nil
+	}
+	if !set && field.defaultValue == "" {
+		return MissingRequiredError{Field: field.path, Var: field.envName, Condition: c.description()}
+	}
+
+	return nil
+}
+
+// description tells when the condition applies, e.g. AUTH_MODE is "oidc".
+func (c condition) description() string {
+	switch c.kind {
+	case requiredIfKind:
+		return fmt.Sprintf("%s is %q", c.envName, c.value)
+	case requiredWithoutKind:
+		return c.envName + " is not set"
+	default:
+		return c.envName + " is set"
+	}
+}
+
+// String describes the condition, e.g. required when AUTH_MODE is "oidc".
+func (c condition) String() string {
+	if c.kind == excludedWithKind {
+		return "excluded when " + c.description()
+	}
+
+	return "required when " + c.description()
+}
+
+// lookup returns the value of the field or variable referenced by the condition, and whether it is set.
+// Referenced fields are set by their default value too.
+func (c condition) lookup(value reflect.Value, plan *structPlan, options Options) (string, bool) {
+	if c.ref < 0 {
+		return options.Source.Lookup(c.envName)
+	}
+
+	ref := &plan.fields[c.ref]
+	if refValue, ok := lookupField(value, ref, options); ok {
+		return refValue, true
+	}
+
+	return ref.defaultValue, ref.defaultValue != ""
+}
+
+// lookupField returns the value of the variable of a decoded field and whether it is set. Map fields are set
+// when at least one of their entries is, and fields falling back to a file when their file is read.
+func lookupField(value reflect.Value, field *fieldPlan, options Options) (string, bool) {
+	if field.value.kind == mapKind {
+		return "", value.Field(field.index).Len() > 0
+	}
+
+	envValue, ok := options.Source.Lookup(field.envName)
+	if !ok && field.fileEnvName != "" {
+		envValue, ok, _ = lookupFile(field, false, options)
+	}
+
+	return envValue, ok
+}
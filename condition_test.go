@@ -0,0 +1,134 @@
+package goenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal_Conditions(t *testing.T) {
+	type OIDC struct {
+		Issuer string `env:"ISSUER" requiredIf:"AUTH_MODE=oidc"`
+	}
+	type Config struct {
+		AuthMode string            `env:"AUTH_MODE" defaultEnv:"none"`
+		Issuer   string            `env:"ISSUER" requiredIf:"AuthMode=oidc AuthMode=oauth"`
+		TLSCert  string            `env:"TLS_CERT" requiredWith:"TLSKey"`
+		TLSKey   string            `env:"TLS_KEY" requiredWith:"TLSCert"`
+		Password string            `env:"PASSWORD" requiredWithout:"Token"`
+		Token    string            `env:"TOKEN" excludedWith:"Password"`
+		Region   string            `env:"REGION" defaultEnv:"eu" requiredWith:"Labels"`
+		Labels   map[string]string `env:"LABELS" excludedWith:"LEGACY_LABELS"`
+		OIDC     OIDC              `envPrefix:"OIDC_"`
+	}
+
+	tests := []struct {
+		name     string
+		source   MapSource
+		expected error
+	}{
+		{"Satisfied", MapSource{
+			"AUTH_MODE": "oidc", "ISSUER": "https://id", "OIDC_ISSUER": "https://id", "PASSWORD": "p", "LABELS_TEAM": "core",
+		}, nil},
+		{"Token instead of password", MapSource{"TOKEN": "t"}, nil},
+		{"Required if", MapSource{"AUTH_MODE": "oauth", "PASSWORD": "p"}, MissingRequiredError{
+			Field: "Issuer", Var: "ISSUER", Condition: `AUTH_MODE is "oauth"`,
+		}},
+		{"Required if variable", MapSource{"AUTH_MODE": "oidc", "ISSUER": "https://id", "PASSWORD": "p"}, MissingRequiredError{
+			Field: "OIDC.Issuer", Var: "OIDC_ISSUER", Condition: `AUTH_MODE is "oidc"`,
+		}},
+		{"Required with", MapSource{"TLS_KEY": "key", "PASSWORD": "p"}, MissingRequiredError{
+			Field: "TLSCert", Var: "TLS_CERT", Condition: "TLS_KEY is set",
+		}},
+		{"Required without", MapSource{}, MissingRequiredError{
+			Field: "Password", Var: "PASSWORD", Condition: "TOKEN is not set",
+		}},
+		{"Excluded with", MapSource{"PASSWORD": "p", "TOKEN": "t"}, ExcludedVariableError{
+			Field: "Token", Var: "TOKEN", Condition: "PASSWORD is set",
+		}},
+		{"Excluded with variable", MapSource{"PASSWORD": "p", "LABELS_TEAM": "core", "LEGACY_LABELS": "team=core"}, ExcludedVariableError{
+			Field: "Labels", Var: "LABELS", Condition: "LEGACY_LABELS is set",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDecoder(WithSource(tt.source)).Decode(&Config{})
+
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+
+	t.Run("Error messages", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"AUTH_MODE": "oauth", "PASSWORD": "p"})).Decode(&Config{})
+		assert.EqualError(t, err, `environment variable ISSUER is required when AUTH_MODE is "oauth" (field Issuer)`)
+
+		err = NewDecoder(WithSource(MapSource{"PASSWORD": "p", "TOKEN": "t"})).Decode(&Config{})
+		assert.EqualError(t, err, "environment variable TOKEN must not be set when PASSWORD is set (field Token)")
+	})
+
+	t.Run("Collected errors", func(t *testing.T) {
+		source := MapSource{"AUTH_MODE": "oidc", "TLS_CERT": "cert"}
+
+		err := NewDecoder(WithSource(source), WithCollectErrors()).Decode(&Config{})
+
+		var multiErr MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 4)
+	})
+
+	t.Run("Prefixed fields", func(t *testing.T) {
+		type Config struct {
+			User     string `env:"USER" requiredWith:"Password"`
+			Password string `env:"PASSWORD"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{"DB_PASSWORD": "p"}), WithPrefix("DB_")).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "User", Var: "DB_USER", Condition: "DB_PASSWORD is set"}, err)
+	})
+
+	t.Run("Unset pointer to struct", func(t *testing.T) {
+		type Config struct {
+			OIDC *OIDC `envPrefix:"OIDC_"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{"AUTH_MODE": "oidc"})).Decode(&Config{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Invalid condition", func(t *testing.T) {
+		type Config struct {
+			Issuer string `env:"ISSUER" requiredIf:"AuthMode"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{})).Decode(&Config{})
+
+		var ruleErr InvalidRuleError
+		assert.True(t, errors.As(err, &ruleErr))
+		assert.Equal(t, "AuthMode", ruleErr.Rule)
+	})
+}
+
+func TestCheckConditions(t *testing.T) {
+	type Config struct {
+		User     string `env:"USER" requiredWith:"Password"`
+		Password string `env:"PASSWORD"`
+	}
+
+	err := CheckConditions(MapSource{"DB_PASSWORD": "p"}, &Config{}, "DB", "DB_")
+	assert.Equal(t, MissingRequiredError{Field: "DB.User", Var: "DB_USER", Condition: "DB_PASSWORD is set"}, err)
+
+	err = CheckConditions(MapSource{"DB_USER": "u", "DB_PASSWORD": "p"}, &Config{}, "DB", "DB_")
+	assert.Nil(t, err)
+
+	t.Run("Plans are cached", func(t *testing.T) {
+		options := defaultOptions()
+		options.Prefix = "DB_"
+		typ := reflect.TypeOf(Config{})
+
+		assert.Same(t, generatedStructPlan(typ, "DB", options), generatedStructPlan(typ, "DB", options))
+		assert.NotSame(t, generatedStructPlan(typ, "DB", options), generatedStructPlan(typ, "", options))
+	})
+}
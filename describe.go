@@ -0,0 +1,293 @@
+package goenv
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Variable describes an environment variable read by Unmarshal.
+type Variable struct {
+	// Name is the name of the variable, including its prefix. For map fields it is the prefix of the
+	// variables holding the entries of the map, see Map.
+	Name string
+
+	// Field is the dotted path of the field from the described struct, e.g. Database.Port.
+	Field string
+
+	// Type is the Go type of the field, e.g. time.Duration or []string.
+	Type string
+
+	// Default is the default value of the field, set when HasDefault is true.
+	Default    string
+	HasDefault bool
+
+	// Required tells whether the variable must be set.
+	Required bool
+
+	// Conditions describe the conditional requirements of the variable, e.g. required when AUTH_MODE is "oidc".
+	Conditions []string
+
+	// FileVar is the name of the variable holding the name of the file read when the variable is not set,
+	// e.g. DB_PASSWORD_FILE. It is empty when the field does not fall back to a file.
+	FileVar string
+
+	// Separator is the separator of the values of slice fields, empty for other fields.
+	Separator string
+
+	// Validate holds the rules of the validate tag of the field, e.g. min=1,max=65535.
+	Validate string
+
+	// Map tells whether the field is a map, whose entries are read from the variables named Name_<KEY>.
+	Map bool
+
+	// Description and Example are taken from the description and example tags of the field.
+	Description string
+	Example     string
+}
+
+// DisplayName returns the name of the variable as shown in documentation, e.g. OPTIONS_<KEY> for maps.
+func (v Variable) DisplayName() string {
+	if v.Map {
+		return v.Name + "_<KEY>"
+	}
+
+	return v.Name
+}
+
+// Schema describes every environment variable read when decoding a struct.
+type Schema struct {
+	Variables []Variable
+}
+
+// Describe returns the Schema of the variables Unmarshal reads into the given struct, in field order.
+// The schema is built from the decoding plan of the struct, so nested structs, prefixes, the naming strategy,
+// map fields, _FILE variables and conditional requirements are described exactly as Unmarshal reads them.
+//
+// Parameters:
+//   - v: A struct, or a pointer to a struct, tagged like the ones given to Unmarshal. Fields can be documented
+//     with the `envDesc:"..."` and `envExample:"..."` tags.
+//
+// Returns:
+//   - Schema: The description of every variable, which can be rendered with its Write methods.
+//   - error: An error if v is not a struct or a pointer to a struct.
+func Describe(v interface{}) (Schema, error) {
+	return defaultDecoder.Describe(v)
+}
+
+// Describe returns the Schema of the variables the Decoder reads into the given struct, see Describe.
+func (d *Decoder) Describe(v interface{}) (Schema, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		actualType := reflect.Invalid.String()
+		if typ != nil {
+			actualType = typ.Kind().String()
+		}
+		return Schema{}, NotStructPtrError{
+			actualType: actualType,
+		}
+	}
+
+	var schema Schema
+	describeStruct(d.plan(typ), &schema, d.options.withDefaults())
+
+	return schema, nil
+}
+
+func describeStruct(plan *structPlan, schema *Schema, options Options) {
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		switch {
+		case field.recursive:
+			// recursive fields are not decoded, see parseField
+		case field.nested != nil:
+			describeStruct(field.nested, schema, options)
+		default:
+			schema.Variables = append(schema.Variables, describeField(plan, field, options))
+		}
+	}
+}
+
+func describeField(plan *structPlan, field *fieldPlan, options Options) Variable {
+	fieldType := plan.typ.Field(field.index)
+	variable := Variable{
+		Name:        field.envName,
+		FileVar:     field.fileEnvName,
+		Field:       field.path,
+		Type:        fieldType.Type.String(),
+		Required:    field.required,
+		Validate:    fieldType.Tag.Get(options.ValidateTagName),
+		Description: fieldType.Tag.Get(options.DescriptionTagName),
+		Example:     fieldType.Tag.Get(options.ExampleTagName),
+	}
+	if field.defaultValue != "" {
+		variable.Default = field.defaultValue
+		variable.HasDefault = true
+	}
+	for _, c := range field.conditions {
+		variable.Conditions = append(variable.Conditions, c.String())
+	}
+
+	value := field.value
+	for value.kind == pointerKind {
+		value = value.elem
+	}
+	switch value.kind {
+	case sliceKind:
+		variable.Separator = value.separator
+	case mapKind:
+		variable.Map = true
+	}
+
+	return variable
+}
+
+// WriteEnvExample renders the schema as a .env.example file. Each variable is preceded by comments holding
+// its description, type, default value and whether it is required. The value of a variable is its example,
+// or else its default value. Map variables are commented out, since their names depend on the map keys.
+func (s Schema) WriteEnvExample(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, variable := range s.Variables {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		if variable.Description != "" {
+			for _, line := range strings.Split(variable.Description, "\n") {
+				fmt.Fprintf(bw, "# %s\n", line)
+			}
+		}
+		fmt.Fprintf(bw, "# %s\n", strings.Join(variable.details(), ", "))
+
+		value := variable.Example
+		if value == "" {
+			value = variable.Default
+		}
+		if variable.Map {
+			fmt.Fprintf(bw, "# %s=%s\n", variable.DisplayName(), quoteDotenv(value))
+			continue
+		}
+		fmt.Fprintf(bw, "%s=%s\n", variable.Name, quoteDotenv(value))
+	}
+
+	return bw.Flush()
+}
+
+// details lists the type, default value, separator, rules and requirement of a variable, for the .env.example comments.
+func (v Variable) details() []string {
+	details := []string{"type: " + v.Type}
+	if v.Required {
+		details = append(details, "required")
+	}
+	details = append(details, v.Conditions...)
+	if v.HasDefault {
+		details = append(details, fmt.Sprintf("default: %q", v.Default))
+	}
+	if v.Separator != "" {
+		details = append(details, fmt.Sprintf("separator: %q", v.Separator))
+	}
+	if v.Validate != "" {
+		details = append(details, fmt.Sprintf("validate: %q", v.Validate))
+	}
+	if v.FileVar != "" {
+		details = append(details, "file: "+v.FileVar)
+	}
+
+	return details
+}
+
+// WriteMarkdown renders the schema as a Markdown table.
+func (s Schema) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("| Variable | Type | Required | Default | Separator | Description | Example |\n")
+	bw.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, variable := range s.Variables {
+		name := "`" + variable.DisplayName() + "`"
+		if variable.FileVar != "" {
+			name += "<br>`" + variable.FileVar + "`"
+		}
+		fmt.Fprintf(bw, "| %s | `%s` | %s | %s | %s | %s | %s |\n",
+			name,
+			variable.Type,
+			markdownText(variable.requirement()),
+			markdownCode(variable.Default, variable.HasDefault),
+			markdownCode(variable.Separator, variable.Separator != ""),
+			markdownText(variable.Description),
+			markdownCode(variable.Example, variable.Example != ""),
+		)
+	}
+
+	return bw.Flush()
+}
+
+// WriteHTML renders the schema as an HTML table.
+func (s Schema) WriteHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<table>\n")
+	bw.WriteString("  <thead>\n")
+	bw.WriteString("    <tr><th>Variable</th><th>Type</th><th>Required</th><th>Default</th><th>Separator</th><th>Description</th><th>Example</th></tr>\n")
+	bw.WriteString("  </thead>\n")
+	bw.WriteString("  <tbody>\n")
+	for _, variable := range s.Variables {
+		name := htmlCode(variable.DisplayName(), true)
+		if variable.FileVar != "" {
+			name += "<br>" + htmlCode(variable.FileVar, true)
+		}
+		fmt.Fprintf(bw, "    <tr><td>%s</td><td><code>%s</code></td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			name,
+			html.EscapeString(variable.Type),
+			html.EscapeString(variable.requirement()),
+			htmlCode(variable.Default, variable.HasDefault),
+			htmlCode(variable.Separator, variable.Separator != ""),
+			strings.ReplaceAll(html.EscapeString(variable.Description), "\n", "<br>"),
+			htmlCode(variable.Example, variable.Example != ""),
+		)
+	}
+	bw.WriteString("  </tbody>\n")
+	bw.WriteString("</table>\n")
+
+	return bw.Flush()
+}
+
+// requirement tells whether the variable is required, for the Markdown and HTML tables: yes, no, or its
+// conditional requirements.
+func (v Variable) requirement() string {
+	if v.Required {
+		return "yes"
+	}
+	if len(v.Conditions) > 0 {
+		return strings.Join(v.Conditions, "; ")
+	}
+
+	return "no"
+}
+
+var (
+	escapeMarkdownText = strings.NewReplacer("|", `\|`, "\n", "<br>", "<", "&lt;", ">", "&gt;")
+	escapeMarkdownCode = strings.NewReplacer("|", `\|`, "\n", " ")
+)
+
+func markdownText(s string) string {
+	return escapeMarkdownText.Replace(s)
+}
+
+func markdownCode(s string, present bool) string {
+	if !present {
+		return ""
+	}
+
+	return "`" + escapeMarkdownCode.Replace(s) + "`"
+}
+
+func htmlCode(s string, present bool) string {
+	if !present {
+		return ""
+	}
+
+	return "<code>" + html.EscapeString(s) + "</code>"
+}
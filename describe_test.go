@@ -0,0 +1,141 @@
+package goenv
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type describeDB struct {
+	Host string `env:"HOST,required" envDesc:"Database host" envExample:"localhost"`
+	Port int    `env:"PORT" defaultEnv:"5432" envValidate:"min=1"`
+}
+
+type describeConfig struct {
+	Name     string            `env:"NAME" envDesc:"Name of the service"`
+	Timeout  time.Duration     `env:"TIMEOUT" defaultEnv:"30s"`
+	Hosts    []string          `env:"HOSTS" envSeparator:";" envDesc:"Hosts | ports"`
+	Labels   map[string]string `env:"LABELS" envExample:"value"`
+	Primary  describeDB        `envPrefix:"PRIMARY_"`
+	Replica  *describeDB       `envPrefix:"REPLICA_"`
+	StartAt  *time.Time        `env:"START_AT"`
+	Ignored  string            `env:"-"`
+	Untagged string
+}
+
+func TestDescribe(t *testing.T) {
+	schema, err := Describe(&describeConfig{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{Name: "NAME", Field: "Name", Type: "string", Description: "Name of the service"},
+		{Name: "TIMEOUT", Field: "Timeout", Type: "time.Duration", Default: "30s", HasDefault: true},
+		{Name: "HOSTS", Field: "Hosts", Type: "[]string", Separator: ";", Description: "Hosts | ports"},
+		{Name: "LABELS", Field: "Labels", Type: "map[string]string", Map: true, Example: "value"},
+		{Name: "PRIMARY_HOST", Field: "Primary.Host", Type: "string", Required: true, Description: "Database host", Example: "localhost"},
+		{Name: "PRIMARY_PORT", Field: "Primary.Port", Type: "int", Default: "5432", HasDefault: true, Validate: "min=1"},
+		{Name: "REPLICA_HOST", Field: "Replica.Host", Type: "string", Required: true, Description: "Database host", Example: "localhost"},
+		{Name: "REPLICA_PORT", Field: "Replica.Port", Type: "int", Default: "5432", HasDefault: true, Validate: "min=1"},
+		{Name: "START_AT", Field: "StartAt", Type: "*time.Time"},
+	}, schema.Variables)
+
+	t.Run("Naming strategy", func(t *testing.T) {
+		schema, err := NewDecoder(WithNaming(UpperSnakeCase), WithPrefix("APP_")).Describe(describeConfig{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "APP_UNTAGGED", schema.Variables[len(schema.Variables)-1].Name)
+	})
+
+	t.Run("Files and conditions", func(t *testing.T) {
+		type Config struct {
+			AuthMode string `env:"AUTH_MODE"`
+			Issuer   string `env:"ISSUER" requiredIf:"AuthMode=oidc"`
+			Password string `env:"PASSWORD,file" requiredWithout:"TOKEN"`
+			Token    string `env:"TOKEN,nofile" excludedWith:"Password"`
+		}
+
+		schema, err := NewDecoder(WithFileFallback()).Describe(&Config{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []Variable{
+			{Name: "AUTH_MODE", FileVar: "AUTH_MODE_FILE", Field: "AuthMode", Type: "string"},
+			{Name: "ISSUER", FileVar: "ISSUER_FILE", Field: "Issuer", Type: "string", Conditions: []string{`required when AUTH_MODE is "oidc"`}},
+			{Name: "PASSWORD", FileVar: "PASSWORD_FILE", Field: "Password", Type: "string", Conditions: []string{"required when TOKEN is not set"}},
+			{Name: "TOKEN", Field: "Token", Type: "string", Conditions: []string{"excluded when PASSWORD is set"}},
+		}, schema.Variables)
+	})
+
+	t.Run("Recursive type", func(t *testing.T) {
+		type Node struct {
+			Name string `env:"NAME"`
+			Next *Node  `envPrefix:"NEXT_"`
+		}
+
+		schema, err := Describe(&Node{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []Variable{{Name: "NAME", Field: "Name", Type: "string"}}, schema.Variables)
+	})
+
+	t.Run("Not a struct", func(t *testing.T) {
+		_, err := Describe(42)
+
+		assert.IsType(t, NotStructPtrError{}, err)
+	})
+}
+
+func TestSchema_Write(t *testing.T) {
+	schema := Schema{Variables: []Variable{
+		{Name: "HOST", Field: "Host", Type: "string", Required: true, Description: "Database host\nwith <tags>", Example: "localhost"},
+		{Name: "HOSTS", Field: "Hosts", Type: "[]string", Separator: ",", Default: "a b", HasDefault: true},
+		{Name: "LABELS", Field: "Labels", Type: "map[string]string", Map: true},
+		{Name: "TOKEN", FileVar: "TOKEN_FILE", Field: "Token", Type: "string", Conditions: []string{"required when PASSWORD is not set"}},
+	}}
+
+	t.Run("Env example", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := schema.WriteEnvExample(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "# Database host\n"+
+			"# with <tags>\n"+
+			"# type: string, required\n"+
+			"HOST=localhost\n"+
+			"\n"+
+			"# type: []string, default: \"a b\", separator: \",\"\n"+
+			"HOSTS=\"a b\"\n"+
+			"\n"+
+			"# type: map[string]string\n"+
+			"# LABELS_<KEY>=\n"+
+			"\n"+
+			"# type: string, required when PASSWORD is not set, file: TOKEN_FILE\n"+
+			"TOKEN=\n", buf.String())
+
+		_, err = ParseDotenv(&buf)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := schema.WriteMarkdown(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "| Variable | Type | Required | Default | Separator | Description | Example |\n"+
+			"| --- | --- | --- | --- | --- | --- | --- |\n"+
+			"| `HOST` | `string` | yes |  |  | Database host<br>with &lt;tags&gt; | `localhost` |\n"+
+			"| `HOSTS` | `[]string` | no | `a b` | `,` |  |  |\n"+
+			"| `LABELS_<KEY>` | `map[string]string` | no |  |  |  |  |\n"+
+			"| `TOKEN`<br>`TOKEN_FILE` | `string` | required when PASSWORD is not set |  |  |  |  |\n", buf.String())
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := schema.WriteHTML(&buf)
+
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "<tr><td><code>HOST</code></td><td><code>string</code></td><td>yes</td><td></td><td></td><td>Database host<br>with &lt;tags&gt;</td><td><code>localhost</code></td></tr>")
+		assert.Contains(t, buf.String(), "<td><code>LABELS_&lt;KEY&gt;</code></td>")
+		assert.Contains(t, buf.String(), "<tr><td><code>TOKEN</code><br><code>TOKEN_FILE</code></td><td><code>string</code></td><td>required when PASSWORD is not set</td>")
+	})
+}
@@ -0,0 +1,69 @@
+package goenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// kubernetesDataDir is the symbolic link Kubernetes swaps atomically to the directory holding the current files
+// of a ConfigMap or Secret volume.
+const kubernetesDataDir = "..data"
+
+// NewDirSource reads a directory where each file holds a variable, named after the file, into a MapSource.
+// This is how Kubernetes mounts ConfigMaps and Secrets, and how Docker mounts secrets in /run/secrets.
+//
+// Files whose name starts with a dot and subdirectories are ignored. Symbolic links are followed. When the
+// directory has a ..data link, as Kubernetes volumes do, the files are read from the directory it points to,
+// so a volume updated while it is read is seen either before or after the update, never halfway.
+//
+// Parameters:
+//   - dir: The directory to read.
+//   - trimNewline: Whether the trailing newline of the files is removed, as files are often written with one.
+//
+// Returns:
+//   - MapSource: The variables of the directory, which can be used as a Source or layered with other sources.
+//     Read the directory again to see later updates.
+//   - error: An error if the directory or one of its files cannot be read.
+func NewDirSource(dir string, trimNewline bool) (MapSource, error) {
+	dataDir, err := filepath.EvalSymlinks(filepath.Join(dir, kubernetesDataDir))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		dataDir = dir
+	}
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	source := make(MapSource, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		filename := filepath.Join(dataDir, entry.Name())
+		// symbolic links are followed to tell files from directories
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		value := string(data)
+		if trimNewline {
+			value = trimTrailingNewline(value)
+		}
+		source[entry.Name()] = value
+	}
+
+	return source, nil
+}
@@ -0,0 +1,110 @@
+package goenv
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles writes files with the given names and contents in dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// mountVolume lays out dir like a Kubernetes ConfigMap volume: the files are written in a timestamped directory
+// pointed to by the ..data link, and each key is a link to its file through ..data.
+func mountVolume(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+	versionDir := filepath.Join(dir, "..2024_01_01_00_00_00."+version)
+	if err := os.Mkdir(versionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, versionDir, files)
+
+	// swap the ..data link atomically, as the kubelet does
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(versionDir), tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, kubernetesDataDir)); err != nil {
+		t.Fatal(err)
+	}
+	for name := range files {
+		_ = os.Symlink(filepath.Join(kubernetesDataDir, name), filepath.Join(dir, name))
+	}
+}
+
+func TestNewDirSource(t *testing.T) {
+	t.Run("Plain directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"DB_HOST": "db\n", "DB_PASSWORD": "s3cret\r\n", ".hidden": "x"})
+		if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		source, err := NewDirSource(dir, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"DB_HOST": "db", "DB_PASSWORD": "s3cret"}, source)
+	})
+
+	t.Run("Newlines kept", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"CERT": "line 1\nline 2\n"})
+
+		source, err := NewDirSource(dir, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"CERT": "line 1\nline 2\n"}, source)
+	})
+
+	t.Run("Kubernetes volume", func(t *testing.T) {
+		dir := t.TempDir()
+		mountVolume(t, dir, "1", map[string]string{"LOG_LEVEL": "info", "LABELS_TEAM": "core"})
+
+		source, err := NewDirSource(dir, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"LOG_LEVEL": "info", "LABELS_TEAM": "core"}, source)
+
+		// the files of an update are read from the new directory
+		mountVolume(t, dir, "2", map[string]string{"LOG_LEVEL": "debug", "LABELS_TEAM": "core", "LABELS_OWNER": "me"})
+
+		source, err = NewDirSource(dir, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"LOG_LEVEL": "debug", "LABELS_TEAM": "core", "LABELS_OWNER": "me"}, source)
+	})
+
+	t.Run("Decoding", func(t *testing.T) {
+		type Config struct {
+			LogLevel string            `env:"LOG_LEVEL"`
+			Labels   map[string]string `env:"LABELS"`
+			Port     int               `env:"PORT"`
+		}
+		dir := t.TempDir()
+		mountVolume(t, dir, "1", map[string]string{"LOG_LEVEL": "info\n", "LABELS_TEAM": "core\n"})
+
+		files, err := NewDirSource(dir, true)
+		assert.Nil(t, err)
+
+		actualStruct := &Config{}
+		source := NewLayeredSource(LastWins, files, MapSource{"PORT": "8080"})
+		err = NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{LogLevel: "info", Labels: map[string]string{"team": "core"}, Port: 8080}, *actualStruct)
+	})
+
+	t.Run("Missing directory", func(t *testing.T) {
+		_, err := NewDirSource(filepath.Join(t.TempDir(), "missing"), true)
+
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,423 @@
+package goenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// DotenvEntry is a single variable assignment read from a dotenv file.
+type DotenvEntry struct {
+	// Key is the name of the variable.
+	Key string
+
+	// Value is the unquoted and unescaped value of the variable.
+	Value string
+
+	// Line is the line on which the assignment starts.
+	Line int
+}
+
+// ParseDotenv reads dotenv formatted assignments from r and returns them in the order they appear.
+//
+// The following syntax is supported:
+//   - blank lines and lines starting with # are ignored
+//   - assignments may be prefixed by "export"
+//   - unquoted values are trimmed, and a # preceded by whitespace starts an inline comment
+//   - single-quoted and backtick-quoted values are taken literally
+//   - double-quoted values support the escape sequences \n, \r, \t, \\, \", \', \` and \$
+//   - quoted values may span multiple lines
+//
+// A syntax error is reported as a DotenvSyntaxError holding the line and column of the problem.
+func ParseDotenv(r io.Reader) ([]DotenvEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := parseDotenvNodes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DotenvEntry
+	for _, node := range nodes {
+		if node.entry != nil {
+			entries = append(entries, *node.entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// FormatDotenv reads a dotenv file from r and writes it to w in a canonical form: "export" prefixes and
+// the whitespace around = are dropped, values are quoted only when needed, as WriteDotenv does, and runs
+// of blank lines are collapsed into one. Comments are kept in place.
+func FormatDotenv(w io.Writer, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	nodes, err := parseDotenvNodes(data)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, node := range nodes {
+		if node.blankBefore {
+			bw.WriteString("\n")
+		}
+		line := node.comment
+		if node.entry != nil {
+			line = node.entry.Key + "=" + quoteDotenv(node.entry.Value)
+			if node.comment != "" {
+				line += " " + node.comment
+			}
+		}
+		bw.WriteString(line + "\n")
+	}
+
+	return bw.Flush()
+}
+
+// dotenvNode is an assignment or a full-line comment of a dotenv file.
+type dotenvNode struct {
+	// entry is nil for full-line comments.
+	entry *DotenvEntry
+
+	// comment is the full-line comment, or the inline comment following the assignment.
+	comment string
+
+	// blankBefore tells whether the node is separated from the previous one by blank lines.
+	blankBefore bool
+}
+
+func parseDotenvNodes(data []byte) ([]dotenvNode, error) {
+	parser := &dotenvParser{src: []rune(string(data)), line: 1, column: 1}
+
+	return parser.parse()
+}
+
+// ReadDotenv reads dotenv formatted assignments from r into a MapSource.
+// When a variable is assigned more than once the last value wins.
+func ReadDotenv(r io.Reader) (MapSource, error) {
+	entries, err := ParseDotenv(r)
+	if err != nil {
+		return nil, err
+	}
+
+	source := make(MapSource, len(entries))
+	for _, entry := range entries {
+		source[entry.Key] = entry.Value
+	}
+
+	return source, nil
+}
+
+// ReadDotenvFile reads the dotenv file with the given name into a MapSource.
+func ReadDotenvFile(filename string) (MapSource, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	source, err := ReadDotenv(file)
+	if syntaxErr, ok := err.(DotenvSyntaxError); ok {
+		syntaxErr.Filename = filename
+		err = syntaxErr
+	}
+
+	return source, err
+}
+
+// NewDotenvSource reads the given dotenv files into a single MapSource which can be used as a Source.
+// Files are read in order, so variables in later files override those in earlier ones.
+// When no filename is given, ".env" is read.
+func NewDotenvSource(filenames ...string) (MapSource, error) {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	source := make(MapSource)
+	for _, filename := range filenames {
+		fileSource, err := ReadDotenvFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileSource {
+			source[key] = value
+		}
+	}
+
+	return source, nil
+}
+
+// LoadDotenv reads the given dotenv files and sets their variables in the process environment.
+// Variables that are already set in the environment are left untouched.
+// When no filename is given, ".env" is read.
+func LoadDotenv(filenames ...string) error {
+	return loadDotenv(filenames, false)
+}
+
+// OverloadDotenv behaves like LoadDotenv but overrides variables that are already set in the environment.
+func OverloadDotenv(filenames ...string) error {
+	return loadDotenv(filenames, true)
+}
+
+func loadDotenv(filenames []string, override bool) error {
+	source, err := NewDotenvSource(filenames...)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range source.Keys() {
+		if _, isPresent := os.LookupEnv(key); isPresent && !override {
+			continue
+		}
+		if err := os.Setenv(key, source[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const eof = -1
+
+var quoteName = map[rune]string{
+	'\'': "single-quoted",
+	'`':  "backtick-quoted",
+}
+
+type dotenvParser struct {
+	src    []rune
+	pos    int
+	line   int
+	column int
+}
+
+func (p *dotenvParser) parse() ([]dotenvNode, error) {
+	var nodes []dotenvNode
+
+	for {
+		line := p.line
+		p.skipWhile(unicode.IsSpace)
+		// the line break ending the previous node is skipped too, so a blank line moves two lines down
+		node := dotenvNode{blankBefore: len(nodes) > 0 && p.line-line > 1}
+
+		switch p.peek() {
+		case eof:
+			return nodes, nil
+		case '#':
+			node.comment = p.parseComment()
+		default:
+			entry, comment, err := p.parseEntry()
+			if err != nil {
+				return nil, err
+			}
+			node.entry, node.comment = &entry, comment
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// parseEntry parses an assignment and returns it along with the inline comment following it, if any.
+func (p *dotenvParser) parseEntry() (DotenvEntry, string, error) {
+	entry := DotenvEntry{Line: p.line}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return entry, "", err
+	}
+	if key == "export" && isBlank(p.peek()) {
+		p.skipWhile(isBlank)
+		if key, err = p.parseKey(); err != nil {
+			return entry, "", err
+		}
+	}
+	entry.Key = key
+
+	p.skipWhile(isBlank)
+	if p.peek() != '=' {
+		return entry, "", p.errorf("expected '=' after variable name %q", key)
+	}
+	p.next()
+	p.skipWhile(isBlank)
+
+	switch quote := p.peek(); quote {
+	case '\'', '`':
+		entry.Value, err = p.parseLiteral(quote)
+	case '"':
+		entry.Value, err = p.parseDoubleQuoted()
+	default:
+		var comment string
+		entry.Value, comment = p.parseUnquoted()
+		return entry, comment, nil
+	}
+	if err != nil {
+		return entry, "", err
+	}
+
+	comment, err := p.parseLineEnd()
+	return entry, comment, err
+}
+
+func (p *dotenvParser) parseKey() (string, error) {
+	start := p.pos
+	if r := p.peek(); r != '_' && !unicode.IsLetter(r) {
+		if r == eof || r == '\n' {
+			return "", p.errorf("expected variable name")
+		}
+		return "", p.errorf("unexpected character %q, expected variable name", r)
+	}
+	p.skipWhile(isKeyRune)
+
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *dotenvParser) parseUnquoted() (value, comment string) {
+	var builder strings.Builder
+	for r := p.peek(); r != eof && r != '\n'; r = p.peek() {
+		// a # starts an inline comment when it follows whitespace
+		if r == '#' && isBlank(p.src[p.pos-1]) {
+			comment = p.parseComment()
+			break
+		}
+		builder.WriteRune(p.next())
+	}
+
+	return strings.TrimRightFunc(builder.String(), unicode.IsSpace), comment
+}
+
+func (p *dotenvParser) parseLiteral(quote rune) (string, error) {
+	line, column := p.line, p.column
+	p.next()
+
+	start := p.pos
+	for {
+		switch p.next() {
+		case eof:
+			return "", p.errorAt(line, column, "unterminated %s value", quoteName[quote])
+		case quote:
+			return string(p.src[start : p.pos-1]), nil
+		}
+	}
+}
+
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	line, column := p.line, p.column
+	p.next()
+
+	var value strings.Builder
+	for {
+		r := p.next()
+		switch r {
+		case eof:
+			return "", p.errorAt(line, column, "unterminated double-quoted value")
+		case '"':
+			return value.String(), nil
+		case '\\':
+			escaped := p.next()
+			switch escaped {
+			case eof:
+				return "", p.errorAt(line, column, "unterminated double-quoted value")
+			case 'n':
+				value.WriteRune('\n')
+			case 'r':
+				value.WriteRune('\r')
+			case 't':
+				value.WriteRune('\t')
+			case '\\', '"', '\'', '`', '$':
+				value.WriteRune(escaped)
+			case '\n':
+				// a backslash at the end of a line continues the value on the next line
+			default:
+				value.WriteRune('\\')
+				value.WriteRune(escaped)
+			}
+		default:
+			value.WriteRune(r)
+		}
+	}
+}
+
+// parseLineEnd makes sure nothing but whitespace or a comment follows a quoted value.
+// It returns the comment, if any.
+func (p *dotenvParser) parseLineEnd() (string, error) {
+	p.skipWhile(isBlank)
+
+	switch r := p.peek(); r {
+	case eof, '\n':
+		return "", nil
+	case '#':
+		return p.parseComment(), nil
+	default:
+		return "", p.errorf("unexpected character %q after quoted value", r)
+	}
+}
+
+// parseComment returns the comment starting at the current position, up to the end of the line.
+func (p *dotenvParser) parseComment() string {
+	start := p.pos
+	p.skipWhile(func(r rune) bool {
+		return r != '\n'
+	})
+
+	return strings.TrimRightFunc(string(p.src[start:p.pos]), unicode.IsSpace)
+}
+
+func (p *dotenvParser) skipWhile(fn func(rune) bool) {
+	for r := p.peek(); r != eof && fn(r); r = p.peek() {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return eof
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) next() rune {
+	r := p.peek()
+	if r == eof {
+		return eof
+	}
+
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
+
+	return r
+}
+
+func (p *dotenvParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.line, p.column, format, args...)
+}
+
+func (p *dotenvParser) errorAt(line, column int, format string, args ...interface{}) error {
+	return DotenvSyntaxError{
+		Line:   line,
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+func isKeyRune(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,177 @@
+package goenv
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Run("Syntax", func(t *testing.T) {
+		data := "# comment\n" +
+			"PLAIN=value\n" +
+			"  INDENTED = spaced value  \n" +
+			"export EXPORTED=1\n" +
+			"export=not a prefix\n" +
+			"EMPTY=\n" +
+			"INLINE=value # comment\n" +
+			"HASH=a#b\n" +
+			"SINGLE='literal \\n $HOME # not a comment'\n" +
+			"DOUBLE=\"tab\\tnew\\nline \\\"quoted\\\" \\$HOME\" # comment\n" +
+			"BACKTICK=`it's \"raw\"`\n" +
+			"MULTI=\"first\n" +
+			"second\"\n" +
+			"WINDOWS=crlf\r\n" +
+			"DOTTED.KEY-NAME=ok\n"
+
+		entries, err := ParseDotenv(strings.NewReader(data))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []DotenvEntry{
+			{Key: "PLAIN", Value: "value", Line: 2},
+			{Key: "INDENTED", Value: "spaced value", Line: 3},
+			{Key: "EXPORTED", Value: "1", Line: 4},
+			{Key: "export", Value: "not a prefix", Line: 5},
+			{Key: "EMPTY", Value: "", Line: 6},
+			{Key: "INLINE", Value: "value", Line: 7},
+			{Key: "HASH", Value: "a#b", Line: 8},
+			{Key: "SINGLE", Value: "literal \\n $HOME # not a comment", Line: 9},
+			{Key: "DOUBLE", Value: "tab\tnew\nline \"quoted\" $HOME", Line: 10},
+			{Key: "BACKTICK", Value: "it's \"raw\"", Line: 11},
+			{Key: "MULTI", Value: "first\nsecond", Line: 12},
+			{Key: "WINDOWS", Value: "crlf", Line: 14},
+			{Key: "DOTTED.KEY-NAME", Value: "ok", Line: 15},
+		}, entries)
+	})
+
+	t.Run("Syntax errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			data     string
+			expected string
+		}{
+			{"Missing equals", "A=1\nB 2", "line 2, column 3: expected '=' after variable name \"B\""},
+			{"Invalid name", "A=1\n  =2", "line 2, column 3: unexpected character '=', expected variable name"},
+			{"Unterminated double quote", "A=1\nB=\"abc\n", "line 2, column 3: unterminated double-quoted value"},
+			{"Unterminated single quote", "A='abc", "line 1, column 3: unterminated single-quoted value"},
+			{"Trailing characters", "A=\"abc\" def", "line 1, column 9: unexpected character 'd' after quoted value"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ParseDotenv(strings.NewReader(tt.data))
+
+				assert.IsType(t, DotenvSyntaxError{}, err)
+				assert.EqualError(t, err, tt.expected)
+			})
+		}
+	})
+}
+
+func TestNewDotenvSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	_ = os.WriteFile(base, []byte("A=1\nB=2\n"), 0o600)
+	_ = os.WriteFile(local, []byte("B=3\n"), 0o600)
+
+	t.Run("Later files override", func(t *testing.T) {
+		source, err := NewDotenvSource(base, local)
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"A": "1", "B": "3"}, source)
+	})
+
+	t.Run("Syntax error reports filename", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.env")
+		_ = os.WriteFile(invalid, []byte("A='1"), 0o600)
+
+		_, err := NewDotenvSource(base, invalid)
+
+		assert.EqualError(t, err, invalid+":1:3: unterminated single-quoted value")
+	})
+
+	t.Run("Unmarshal from dotenv source", func(t *testing.T) {
+		source, err := NewDotenvSource(base, local)
+		assert.Nil(t, err)
+
+		actualStruct := &struct {
+			A int `env:"A"`
+			B int `env:"B"`
+		}{}
+		err = NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, actualStruct.A)
+		assert.Equal(t, 3, actualStruct.B)
+	})
+}
+
+func TestLoadDotenv(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	_ = os.WriteFile(filename, []byte("LOAD_DOTENV_SET=file\nLOAD_DOTENV_NEW=file\n"), 0o600)
+	t.Setenv("LOAD_DOTENV_SET", "process")
+
+	err := LoadDotenv(filename)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "process", os.Getenv("LOAD_DOTENV_SET"))
+	assert.Equal(t, "file", os.Getenv("LOAD_DOTENV_NEW"))
+
+	err = OverloadDotenv(filename)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "file", os.Getenv("LOAD_DOTENV_SET"))
+	_ = os.Unsetenv("LOAD_DOTENV_NEW")
+}
+
+func TestFormatDotenv(t *testing.T) {
+	t.Run("Normalises assignments", func(t *testing.T) {
+		data := "\n# database\n" +
+			"export DB_HOST = localhost   # inline\n" +
+			"DB_NAME='my app'\n" +
+			"\n\n\n" +
+			"  # cache\n" +
+			"CACHE_URL=\"redis://cache\"\n" +
+			"MULTI=\"first\n" +
+			"second\"\n" +
+			"\n" +
+			"EMPTY=\n" +
+			"# trailing comment\n"
+
+		var buf strings.Builder
+		err := FormatDotenv(&buf, strings.NewReader(data))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "# database\n"+
+			"DB_HOST=localhost # inline\n"+
+			"DB_NAME=\"my app\"\n"+
+			"\n"+
+			"# cache\n"+
+			"CACHE_URL=redis://cache\n"+
+			"MULTI=\"first\\nsecond\"\n"+
+			"\n"+
+			"EMPTY=\n"+
+			"# trailing comment\n", buf.String())
+	})
+
+	t.Run("Output parses to the same entries", func(t *testing.T) {
+		data := "A=\"tab\\there\" # c\nB='#not a comment'\nC=`back\"tick`\n"
+
+		var buf strings.Builder
+		err := FormatDotenv(&buf, strings.NewReader(data))
+		assert.Nil(t, err)
+
+		before, _ := ReadDotenv(strings.NewReader(data))
+		after, err := ReadDotenv(strings.NewReader(buf.String()))
+		assert.Nil(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		err := FormatDotenv(&strings.Builder{}, strings.NewReader("A=1\nB 2"))
+
+		assert.IsType(t, DotenvSyntaxError{}, err)
+	})
+}
@@ -1,37 +1,31 @@
 package goenv
 
 import (
+	"fmt"
 	"github.com/ilhamtubagus/condutil"
-	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 )
 
-type Options struct {
-	// TagName is the tag name used to specify the environment variable name.
-	TagName string
+// Decoder populates structs from environment variables using a fixed set of Options.
+// A Decoder is safe for concurrent use. The zero value is a Decoder using the default options, like Unmarshal.
+type Decoder struct {
+	options Options
 
-	// DefaultTagName is the default tag name to be used if no tag name is specified in the struct fields.
-	DefaultTagName string
-
-	// SeparatorTagName is the tag name used to specify the separator for splitting the environment variable value into multiple values.
-	SeparatorTagName string
-
-	// separator is the separator used to split the environment variable value into multiple values (used on slices or maps).
-	separator string
-
-	// FuncMap is a map of custom parsing functions for specific types.
-	FuncMap map[reflect.Type]ParseFunc
+	// plans caches the structPlan of every struct type decoded, so the options must not change once set.
+	plans sync.Map
 }
 
-func defaultOptions() Options {
-	return Options{
-		TagName:          "env",
-		DefaultTagName:   "defaultEnv",
-		separator:        ",",
-		FuncMap:          nil,
-		SeparatorTagName: "envSeparator",
+// NewDecoder creates a Decoder configured with the default options modified by opts.
+func NewDecoder(opts ...Option) *Decoder {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
 	}
+
+	return &Decoder{options: options.withDefaults()}
 }
 
 // Unmarshal populates the fields of the target struct with values from environment variables.
@@ -46,7 +40,93 @@ func defaultOptions() Options {
 //   - error: An error if any issues occur during the unmarshalling process, such as
 //     type conversion errors or missing required environment variables.
 //     Returns nil if the unmarshalling is successful.
+//
+// Structs implementing StructUnmarshaler, usually through generated code, decode themselves without reflection.
 func Unmarshal(target interface{}) error {
+	if unmarshaler, ok := target.(StructUnmarshaler); ok && isNonNilPtr(target) {
+		return unmarshaler.UnmarshalEnv(OSSource{})
+	}
+
+	return defaultDecoder.Decode(target)
+}
+
+// defaultDecoder is used by Unmarshal, so the decoding plans of structs are shared by every call.
+var defaultDecoder = NewDecoder()
+
+// UnmarshalWithOptions behaves like Unmarshal but uses the given options.
+// Fields left empty in options fall back to their default values.
+//
+// Like Unmarshal, it caches the decoding plan of the struct, once per set of options. Plans are not cached when
+// options set a Naming strategy or a FuncMap, which cannot be compared: create a Decoder with NewDecoder to decode
+// a struct repeatedly with these.
+func UnmarshalWithOptions(target interface{}, options Options) error {
+	options = options.withDefaults()
+	key, ok := newPlanOptions(options)
+	if !ok {
+		decoder := &Decoder{options: options}
+		return decoder.Decode(target)
+	}
+
+	decoder, ok := optionsDecoders.Load(key)
+	if !ok {
+		// the decoder only compiles plans, so it does not keep the source or the formatters of the caller
+		cachedOptions := options
+		cachedOptions.Source = nil
+		cachedOptions.FormatMap = nil
+		decoder, _ = optionsDecoders.LoadOrStore(key, &Decoder{options: cachedOptions})
+	}
+
+	return decoder.(*Decoder).decode(target, options)
+}
+
+// optionsDecoders caches the Decoder used by UnmarshalWithOptions for every planOptions.
+var optionsDecoders sync.Map
+
+// planOptions holds the options compiled into decoding plans, which key the decoders cached by
+// UnmarshalWithOptions. Options read only while decoding, such as Source or CollectErrors, are left out.
+type planOptions struct {
+	tagName, defaultTagName, prefixTagName, separatorTagName, layoutTagName, validateTagName string
+	requiredIfTagName, requiredWithTagName, requiredWithoutTagName, excludedWithTagName      string
+	separator, prefix, fileSuffix                                                            string
+	autoPrefix, fileFallback                                                                 bool
+	durationUnit                                                                             time.Duration
+}
+
+// newPlanOptions returns the planOptions of options, or false when options set a Naming strategy or a FuncMap.
+func newPlanOptions(options Options) (planOptions, bool) {
+	if options.Naming != nil || len(options.FuncMap) > 0 {
+		return planOptions{}, false
+	}
+
+	return planOptions{
+		tagName:                options.TagName,
+		defaultTagName:         options.DefaultTagName,
+		prefixTagName:          options.PrefixTagName,
+		separatorTagName:       options.SeparatorTagName,
+		layoutTagName:          options.LayoutTagName,
+		validateTagName:        options.ValidateTagName,
+		requiredIfTagName:      options.RequiredIfTagName,
+		requiredWithTagName:    options.RequiredWithTagName,
+		requiredWithoutTagName: options.RequiredWithoutTagName,
+		excludedWithTagName:    options.ExcludedWithTagName,
+		separator:              options.Separator,
+		prefix:                 options.Prefix,
+		fileSuffix:             options.FileSuffix,
+		autoPrefix:             options.AutoPrefix,
+		fileFallback:           options.FileFallback,
+		durationUnit:           options.DurationUnit,
+	}, true
+}
+
+// Decode populates the fields of the target struct with values from environment variables.
+// The target must be a pointer to a struct, see Unmarshal for details.
+func (d *Decoder) Decode(target interface{}) error {
+	return d.decode(target, d.options)
+}
+
+// decode populates the target struct following the plans of d, with options which must compile into the same
+// plans as the options of d.
+func (d *Decoder) decode(target interface{}, options Options) error {
 	targetRef := reflect.ValueOf(target)
 
 	if targetRef.Kind() != reflect.Ptr {
@@ -55,199 +135,331 @@ func Unmarshal(target interface{}) error {
 		}
 	}
 
-	typeRef := reflect.TypeOf(target).Elem()
 	if targetRef.Elem().Kind() != reflect.Struct {
 		return NotStructPtrError{
 			actualType: targetRef.Elem().Kind().String(),
 		}
 	}
 
-	value := targetRef.Elem()
-	options := defaultOptions()
+	// options are completed here, so the zero value of Decoder is usable
+	options = options.withDefaults()
+	options.Source = newSnapshotSource(options.Source)
 
-	for i := 0; i < value.NumField(); i++ {
-		field := value.Field(i)
-		fieldType := typeRef.Field(i)
+	return decodeStruct(targetRef.Elem(), d.plan(targetRef.Elem().Type()), options)
+}
 
-		if err := parseField(field, fieldType, options); err != nil {
+// plan returns the decoding plan of a struct type, compiling it on first use.
+func (d *Decoder) plan(typ reflect.Type) *structPlan {
+	if plan, ok := d.plans.Load(typ); ok {
+		return plan.(*structPlan)
+	}
+	plan, _ := d.plans.LoadOrStore(typ, compileStruct(typ, "", d.options.withDefaults()))
+
+	return plan.(*structPlan)
+}
+
+func isNonNilPtr(target interface{}) bool {
+	value := reflect.ValueOf(target)
+
+	return value.Kind() == reflect.Ptr && !value.IsNil()
+}
+
+// decodeStruct populates the fields of a struct value following its plan, then checks the conditional
+// requirements of its fields and validates it when it implements Validator.
+//
+// By default decoding stops at the first failing field. When options.CollectErrors is set every field is
+// decoded and the failures are returned together as a MultiError.
+func decodeStruct(value reflect.Value, plan *structPlan, options Options) error {
+	var errs []error
+
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		if err := parseField(value.Field(field.index), field, options); err != nil {
+			if !options.CollectErrors {
+				return err
+			}
+			errs = appendErrors(errs, err)
+		}
+	}
+
+	// scratch values decoded to look for present variables are neither checked nor validated
+	if _, ok := options.Source.(*presenceSource); ok {
+		return nil
+	}
+
+	for _, err := range checkConditions(value, plan, options) {
+		if !options.CollectErrors {
 			return err
 		}
+		errs = append(errs, err)
 	}
-	return nil
+
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return validateStruct(value, plan)
 }
 
-func parseField(field reflect.Value, fieldType reflect.StructField, options Options) error {
+func parseField(field reflect.Value, plan *fieldPlan, options Options) error {
+	if plan.nested == nil {
+		return parseEnv(field, plan, options)
+	}
+	// structs are not decoded again inside themselves, which would never end for recursive types
+	if plan.recursive {
+		return nil
+	}
+
 	// Recursively parse nested structs
-	if field.Kind() == reflect.Struct {
-		return Unmarshal(field.Addr().Interface())
+	if field.Kind() == reflect.Ptr {
+		return parseStructPtr(field, plan.nested, options)
 	}
+	return decodeStruct(field, plan.nested, options)
+}
 
-	if err := parseEnv(field, fieldType, options); err != nil {
-		return err
+// nestedOptions returns the options used to decode the nested struct of a field, where the prefix of the field
+// is appended to the current prefix. The prefix is taken from the prefix tag of the field, or derived from its
+// name with options.Naming (UpperSnakeCase by default) when options.AutoPrefix is set. Embedded structs are not
+// prefixed automatically.
+func nestedOptions(fieldType reflect.StructField, options Options) Options {
+	prefix, ok := fieldType.Tag.Lookup(options.PrefixTagName)
+	if !ok && options.AutoPrefix && !fieldType.Anonymous {
+		naming := options.Naming
+		if naming == nil {
+			naming = UpperSnakeCase
+		}
+		prefix = naming(fieldType.Name) + "_"
 	}
+	options.Prefix += prefix
 
-	return nil
+	return options
 }
 
-func parseEnv(field reflect.Value, fieldType reflect.StructField, options Options) error {
-	envTag := fieldType.Tag.Get(options.TagName)
-	// skip parsing when env tag is empty
-	if condutil.IsZeroValue(envTag) {
+// parseStructPtr populates a pointer to a nested struct. A nil pointer is only allocated when at least one
+// variable of the nested struct is present, or when options.AlwaysAllocateStructs is set, so an unconfigured
+// struct can be told apart from a configured one holding zero values.
+func parseStructPtr(field reflect.Value, plan *structPlan, options Options) error {
+	if !field.IsNil() {
+		return decodeStruct(field.Elem(), plan, options)
+	}
+
+	if !options.AlwaysAllocateStructs && !hasPresentEnv(plan, options) {
 		return nil
 	}
 
-	envValue, isPresent := os.LookupEnv(envTag)
-	// use default value if environment variable is not found
-	if !isPresent && field.Kind() != reflect.Map {
-		return parseDefaultEnv(field, fieldType, options)
+	value := reflect.New(plan.typ)
+	if err := decodeStruct(value.Elem(), plan, options); err != nil {
+		return err
 	}
+	field.Set(value)
 
-	return setFieldValue(field, fieldType, envValue, options)
+	return nil
 }
 
-func parseDefaultEnv(field reflect.Value, fieldType reflect.StructField, options Options) error {
-	defaultValue := fieldType.Tag.Get(options.DefaultTagName)
-	if condutil.IsZeroValue(defaultValue) {
-		return nil
+// hasPresentEnv reports whether any variable of a struct is present, by decoding a scratch value
+// of the struct with every error collected and discarded.
+func hasPresentEnv(plan *structPlan, options Options) bool {
+	tracker := &presenceSource{Source: options.Source}
+	options.Source = tracker
+	options.CollectErrors = true
+	_ = decodeStruct(reflect.New(plan.typ).Elem(), plan, options)
+
+	return tracker.found
+}
+
+func parseEnv(field reflect.Value, plan *fieldPlan, options Options) error {
+	err := parseEnvValue(field, plan, options)
+	// collected errors are reported together, so each of them must tell which field it belongs to
+	if err != nil && options.CollectErrors {
+		return withFieldContext(err, plan.path, plan.envName)
 	}
 
-	return setFieldValue(field, fieldType, defaultValue, options)
+	return err
 }
 
-func setFieldValue(field reflect.Value, fieldType reflect.StructField, envValue string, options Options) error {
-	parseFunc, ok := options.FuncMap[fieldType.Type]
-	if ok {
-		parsedValue, err := parseFunc(envValue)
-		if err != nil {
+func parseEnvValue(field reflect.Value, plan *fieldPlan, options Options) error {
+	if plan.err != nil {
+		return plan.err
+	}
+	// values of sensitive fields are kept out of errors
+	if plan.sensitive {
+		options.RedactValues = true
+	}
+
+	envValue, isPresent := options.Source.Lookup(plan.envName)
+	if field.Kind() == reflect.Map {
+		if err := setFieldValue(field, plan.value, plan.path, plan.envName, envValue, options); err != nil {
 			return err
 		}
-		field.Set(reflect.ValueOf(parsedValue))
+		if plan.required && field.Len() == 0 {
+			return MissingRequiredError{Field: plan.path, Var: plan.envName}
+		}
 
 		return nil
 	}
 
-	parseFunc, ok = defaultParser[field.Kind()]
-	if ok {
-		parsedValue, err := parseFunc(envValue)
+	if plan.fileEnvName != "" {
+		fileValue, isFilePresent, err := lookupFile(plan, isPresent, options)
 		if err != nil {
 			return err
 		}
-		field.Set(reflect.ValueOf(parsedValue))
+		if isFilePresent {
+			envValue, isPresent = fileValue, true
+		}
+	}
 
-		return nil
+	// use default value if environment variable is not found
+	if !isPresent {
+		return parseDefaultEnv(field, plan, options)
 	}
 
-	switch field.Kind() {
-	case reflect.Slice:
-		err := handleSlice(field, fieldType, envValue, options)
-		if err != nil {
-			return err
+	return setFieldValue(field, plan.value, plan.path, plan.envName, envValue, options)
+}
+
+func parseDefaultEnv(field reflect.Value, plan *fieldPlan, options Options) error {
+	if condutil.IsZeroValue(plan.defaultValue) {
+		if plan.required {
+			return MissingRequiredError{Field: plan.path, Var: plan.envName}
 		}
-	case reflect.Map:
-		err := handleMap(field, fieldType, options)
-		if err != nil {
+		return nil
+	}
+
+	return setFieldValue(field, plan.value, plan.path, plan.envName, plan.defaultValue, options)
+}
+
+func setFieldValue(field reflect.Value, plan *valuePlan, path, envName, envValue string, options Options) error {
+	switch plan.kind {
+	case pointerKind:
+		ptr := reflect.New(plan.typ.Elem())
+		if err := setFieldValue(ptr.Elem(), plan.elem, path, envName, envValue, options); err != nil {
 			return err
 		}
-	default:
-		return NoParserFoundError{fieldType.Name}
+		field.Set(ptr)
+		return nil
+	case sliceKind:
+		return handleSlice(field, plan, path, envName, envValue, options)
+	case mapKind:
+		return handleMap(field, plan, path, envName, options)
 	}
 
+	value, err := plan.parse(envValue)
+	if err != nil {
+		return newParseError(err, path, envName, envValue, plan.typ, options)
+	}
+	if err := plan.validate(value, path, envName, envValue, options); err != nil {
+		return err
+	}
+	field.Set(value)
+
 	return nil
 }
 
-func parseMapValue(field reflect.Value, fieldType reflect.StructField, value string, options Options) (reflect.Value, error) {
-	parseFunc, ok := options.FuncMap[field.Type().Elem()]
-	if ok {
-		parsedValue, err := parseFunc(value)
-		if err != nil {
-			return reflect.Value{}, err
-		}
-
-		return reflect.ValueOf(parsedValue), nil
+// hasParser reports whether values of typ are parsed as a whole, through a custom parser, a built-in parser
+// of the type, the parser of the value of a Secret or an unmarshaler interface, rather than by their kind.
+// Pointers to types with a custom or built-in parser are not: they are allocated and their element parsed
+// with its parser, even when the pointer type implements an unmarshaler interface, as *time.Time does.
+func hasParser(typ reflect.Type, options Options) bool {
+	if hasTypeParser(typ, options) {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr && hasTypeParser(typ.Elem(), options) {
+		return false
 	}
 
-	parseFunc, ok = defaultParser[field.Type().Elem().Kind()]
-	if ok {
-		parsedValue, err := parseFunc(value)
-		if err != nil {
-			return reflect.Value{}, err
-		}
+	return isSecret(typ) || isUnmarshaler(typ)
+}
 
-		return reflect.ValueOf(parsedValue), nil
+// hasTypeParser reports whether typ has a custom parser or a built-in parser.
+func hasTypeParser(typ reflect.Type, options Options) bool {
+	if _, ok := options.FuncMap[typ]; ok {
+		return true
 	}
+	_, ok := defaultTypeParser[typ]
 
-	return reflect.Value{}, NoParserFoundError{fieldType.Name}
+	return ok
 }
 
-func handleMap(field reflect.Value, fieldType reflect.StructField, options Options) error {
-	if field.Type().Key().Kind() != reflect.String {
-		return InvalidMapKeyError
+// convertValue converts a value returned by a ParseFunc into the given type, so parsers of a kind
+// can be used for named types such as `type Port int`.
+func convertValue(parsedValue interface{}, typ reflect.Type) (reflect.Value, error) {
+	if parsedValue == nil {
+		return reflect.Zero(typ), nil
 	}
 
-	matchingEnv := make(map[string]string)
+	value := reflect.ValueOf(parsedValue)
+	if value.Type().AssignableTo(typ) {
+		return value, nil
+	}
+	if value.Kind() == typ.Kind() && value.Type().ConvertibleTo(typ) {
+		return value.Convert(typ), nil
+	}
 
-	// Retrieve all environment variables
-	envVars := os.Environ()
-	envTag := fieldType.Tag.Get(options.TagName)
+	return reflect.Value{}, fmt.Errorf("parser returned a value of type %s", value.Type())
+}
 
-	for _, env := range envVars {
-		// Split into key and value
-		parts := strings.SplitN(env, "=", 2)
-		if len(parts) != 2 {
-			return InvalidEnvironmentVariableError
-		}
-		key, value := parts[0], parts[1]
+// newParseError wraps an error returned while parsing a value in a ParseError.
+// Errors telling that the type cannot be parsed at all are returned unchanged.
+func newParseError(err error, path, envName, value string, typ reflect.Type, options Options) error {
+	if _, ok := err.(NoParserFoundError); ok {
+		return err
+	}
 
-		// Check if the key starts with the prefix
-		if strings.HasPrefix(key, envTag) {
-			mapKey := strings.TrimPrefix(key, envTag+"_")
-			mapKey = snakeToCamelCase(mapKey)
-			matchingEnv[mapKey] = value
-		}
+	parseErr := ParseError{
+		Field: path,
+		Var:   envName,
+		Value: value,
+		Type:  typ,
+		Err:   err,
+	}
+	if options.RedactValues {
+		return parseErr.redact()
 	}
 
-	// Create a new map and set it to the field
-	newMap := reflect.MakeMap(field.Type())
-	for k, v := range matchingEnv {
-		value, err := parseMapValue(newMap, fieldType, v, options)
+	return parseErr
+}
+
+func handleMap(field reflect.Value, plan *valuePlan, path, envName string, options Options) error {
+	if plan.typ.Key().Kind() != reflect.String {
+		return InvalidMapKeyError
+	}
+
+	// Create a new map from the variables starting with the prefix and set it to the field
+	newMap := reflect.MakeMap(plan.typ)
+	for _, key := range options.Source.Keys() {
+		if !strings.HasPrefix(key, envName) {
+			continue
+		}
+		mapKey := snakeToCamelCase(strings.TrimPrefix(key, envName+"_"))
+		envValue, _ := options.Source.Lookup(key)
+		value, err := plan.elem.parse(envValue)
 		if err != nil {
+			return newParseError(err, path+"["+mapKey+"]", key, envValue, plan.elem.typ, options)
+		}
+		if err := plan.elem.validate(value, path+"["+mapKey+"]", key, envValue, options); err != nil {
 			return err
 		}
-		newMap.SetMapIndex(reflect.ValueOf(k), value)
+		newMap.SetMapIndex(reflect.ValueOf(mapKey).Convert(plan.typ.Key()), value)
 	}
 	field.Set(newMap)
 
 	return nil
 }
 
-func handleSlice(field reflect.Value, fieldType reflect.StructField, value string, options Options) error {
-	separator := fieldType.Tag.Get(options.SeparatorTagName)
-	if condutil.IsZeroValue(separator) {
-		separator = options.separator
-	}
-	values := strings.Split(value, separator)
-	typee := field.Type().Elem()
-	if typee.Kind() == reflect.Ptr {
-		typee = typee.Elem()
-	}
+func handleSlice(field reflect.Value, plan *valuePlan, path, envName, value string, options Options) error {
+	values := strings.Split(value, plan.separator)
 
-	parserFunc, ok := options.FuncMap[reflect.TypeOf(field)]
-	if !ok {
-		parserFunc, ok = defaultParser[typee.Kind()]
-		if !ok {
-			return NoParserFoundError{fieldType: fieldType.Name}
-		}
-	}
-	result := reflect.MakeSlice(fieldType.Type, 0, len(values))
-	for _, part := range values {
-		r, err := parserFunc(part)
+	result := reflect.MakeSlice(plan.typ, 0, len(values))
+	for i, part := range values {
+		v, err := plan.elem.parse(part)
 		if err != nil {
-			return NoParserFoundError{fieldType: fieldType.Name}
+			return newParseError(err, fmt.Sprintf("%s[%d]", path, i), envName, part, plan.elem.typ, options)
+		}
+		if err := plan.elem.validate(v, fmt.Sprintf("%s[%d]", path, i), envName, part, options); err != nil {
+			return err
 		}
-		v := reflect.ValueOf(r).Convert(typee)
-		if fieldType.Type.Elem().Kind() == reflect.Ptr {
-			v = reflect.New(typee)
-			v.Elem().Set(reflect.ValueOf(r).Convert(typee))
+		if plan.elemPtr {
+			ptr := reflect.New(plan.elem.typ)
+			ptr.Elem().Set(v)
+			v = ptr
 		}
 		result = reflect.Append(result, v)
 	}
@@ -1,27 +1,29 @@
 package goenv
 
 import (
+	"container/list"
+	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net"
+	"net/netip"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func loadEnvFromString(envString string) {
-	lines := strings.Split(envString, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Ignore empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		// Split key and value
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			_ = os.Setenv(key, value)
-		}
+	source, err := ReadDotenv(strings.NewReader(envString))
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range source {
+		_ = os.Setenv(key, value)
 	}
 }
 
@@ -441,3 +443,884 @@ func TestUnmarshal_Map(t *testing.T) {
 		assert.IsType(t, NoParserFoundError{}, err)
 	})
 }
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	t.Run("Custom tag names and separator", func(t *testing.T) {
+		envData := `
+			OPT_NAME=goenv
+			OPT_PORTS=80|443
+		`
+		loadEnvFromString(envData)
+		type Config struct {
+			Name    string `cfg:"OPT_NAME"`
+			Ports   []int  `cfg:"OPT_PORTS"`
+			Timeout int    `cfg:"OPT_TIMEOUT" fallback:"30"`
+		}
+
+		actualStruct := &Config{}
+		err := UnmarshalWithOptions(actualStruct, Options{
+			TagName:        "cfg",
+			DefaultTagName: "fallback",
+			Separator:      "|",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Name: "goenv", Ports: []int{80, 443}, Timeout: 30}, *actualStruct)
+	})
+
+	t.Run("Nested struct keeps options", func(t *testing.T) {
+		envData := `
+			OPT_NESTED_HOSTS=a;b
+		`
+		loadEnvFromString(envData)
+		type Nested struct {
+			Hosts []string `cfg:"OPT_NESTED_HOSTS"`
+		}
+		type Config struct {
+			Nested Nested
+		}
+
+		actualStruct := &Config{}
+		err := UnmarshalWithOptions(actualStruct, Options{TagName: "cfg", Separator: ";"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b"}, actualStruct.Nested.Hosts)
+	})
+
+	t.Run("Plans are cached per options", func(t *testing.T) {
+		type Config struct {
+			Port int `cfg:"PORT"`
+		}
+		options := Options{TagName: "cfg", Source: MapSource{"PORT": "80"}}
+		key, ok := newPlanOptions(options.withDefaults())
+		assert.True(t, ok)
+
+		first, second := &Config{}, &Config{}
+		assert.Nil(t, UnmarshalWithOptions(first, options))
+		options.Source = MapSource{"PORT": "443"}
+		assert.Nil(t, UnmarshalWithOptions(second, options))
+
+		assert.Equal(t, 80, first.Port)
+		assert.Equal(t, 443, second.Port)
+		decoder, ok := optionsDecoders.Load(key)
+		assert.True(t, ok)
+		assert.Nil(t, decoder.(*Decoder).options.Source)
+
+		otherKey, _ := newPlanOptions(Options{TagName: "cfg", Separator: ";"}.withDefaults())
+		assert.NotEqual(t, key, otherKey)
+	})
+
+	t.Run("Plans are not cached with a FuncMap or a Naming strategy", func(t *testing.T) {
+		_, ok := newPlanOptions(Options{FuncMap: map[reflect.Type]ParseFunc{}}.withDefaults())
+		assert.True(t, ok)
+		_, ok = newPlanOptions(Options{FuncMap: map[reflect.Type]ParseFunc{reflect.TypeOf(0): nil}}.withDefaults())
+		assert.False(t, ok)
+		_, ok = newPlanOptions(Options{Naming: UpperCase}.withDefaults())
+		assert.False(t, ok)
+	})
+}
+
+func TestDecoder(t *testing.T) {
+	type Level int
+	levelParser := func(v string) (interface{}, error) {
+		switch v {
+		case "debug":
+			return Level(0), nil
+		case "info":
+			return Level(1), nil
+		}
+		return nil, errors.New("unknown level")
+	}
+
+	t.Run("Custom parser", func(t *testing.T) {
+		envData := `
+			DECODER_LEVEL=info
+			DECODER_LEVELS=debug:info
+		`
+		loadEnvFromString(envData)
+		type Config struct {
+			Level  Level   `env:"DECODER_LEVEL"`
+			Levels []Level `env:"DECODER_LEVELS"`
+		}
+
+		decoder := NewDecoder(
+			WithParser(reflect.TypeOf(Level(0)), levelParser),
+			WithSeparator(":"),
+		)
+		actualStruct := &Config{}
+		err := decoder.Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Level: 1, Levels: []Level{0, 1}}, *actualStruct)
+	})
+
+	t.Run("Custom parser error", func(t *testing.T) {
+		envData := `
+			DECODER_LEVEL=trace
+		`
+		loadEnvFromString(envData)
+		type Config struct {
+			Level Level `env:"DECODER_LEVEL"`
+		}
+
+		decoder := NewDecoder(WithFuncMap(map[reflect.Type]ParseFunc{
+			reflect.TypeOf(Level(0)): levelParser,
+		}))
+		err := decoder.Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.EqualError(t, parseErr.Err, "unknown level")
+	})
+
+	t.Run("Custom separator tag name", func(t *testing.T) {
+		envData := `
+			DECODER_NAMES=a b c
+		`
+		loadEnvFromString(envData)
+		type Config struct {
+			Names []string `env:"DECODER_NAMES" sep:" "`
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSeparatorTagName("sep")).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, actualStruct.Names)
+	})
+
+	t.Run("Not struct pointer", func(t *testing.T) {
+		err := NewDecoder(WithTagName("cfg")).Decode(struct{}{})
+
+		assert.IsType(t, NotStructPtrError{}, err)
+	})
+
+	t.Run("Zero value", func(t *testing.T) {
+		type Config struct {
+			Port  int      `env:"ZERO_DECODER_PORT"`
+			Hosts []string `env:"ZERO_DECODER_HOSTS" defaultEnv:"a,b"`
+		}
+		t.Setenv("ZERO_DECODER_PORT", "8080")
+
+		var decoder Decoder
+		actualStruct := &Config{}
+		err := decoder.Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Port: 8080, Hosts: []string{"a", "b"}}, *actualStruct)
+	})
+
+	t.Run("Plans are cached per type", func(t *testing.T) {
+		type Config struct {
+			Port int `env:"PORT"`
+		}
+
+		decoder := NewDecoder()
+		typ := reflect.TypeOf(Config{})
+		assert.Same(t, decoder.plan(typ), decoder.plan(typ))
+		assert.NotSame(t, decoder.plan(typ), NewDecoder().plan(typ))
+	})
+
+	t.Run("Recursive types are compiled once", func(t *testing.T) {
+		type Node struct {
+			Name string `env:"NAME"`
+			Next *Node  `envPrefix:"NEXT_"`
+		}
+
+		plan := NewDecoder(WithAlwaysAllocateStructs()).plan(reflect.TypeOf(Node{}))
+		assert.Same(t, plan, plan.fields[1].nested)
+	})
+
+	t.Run("Concurrent decoding", func(t *testing.T) {
+		type Config struct {
+			Port   int               `env:"PORT"`
+			Labels map[string]string `env:"LABELS"`
+		}
+		decoder := NewDecoder(WithSource(MapSource{"PORT": "80", "LABELS_APP": "goenv"}))
+
+		var wg sync.WaitGroup
+		results := make([]Config, 8)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = decoder.Decode(&results[i])
+			}()
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			assert.Equal(t, Config{Port: 80, Labels: map[string]string{"app": "goenv"}}, result)
+		}
+	})
+}
+
+// countingSource counts the calls to the Keys method of a MapSource.
+type countingSource struct {
+	MapSource
+	keysCalls int
+}
+
+func (s *countingSource) Keys() []string {
+	s.keysCalls++
+	return s.MapSource.Keys()
+}
+
+func TestDecoder_Snapshot(t *testing.T) {
+	type Config struct {
+		Labels  map[string]string `env:"LABELS"`
+		Options map[string]int    `env:"OPTIONS"`
+	}
+
+	t.Run("Keys are listed once per decode", func(t *testing.T) {
+		source := &countingSource{MapSource: MapSource{"LABELS_APP": "goenv", "OPTIONS_SIZE": "3"}}
+		decoder := NewDecoder(WithSource(source))
+
+		actualStruct := &Config{}
+		err := decoder.Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Labels: map[string]string{"app": "goenv"}, Options: map[string]int{"size": 3}}, *actualStruct)
+		assert.Equal(t, 1, source.keysCalls)
+
+		_ = decoder.Decode(&Config{})
+		assert.Equal(t, 2, source.keysCalls)
+	})
+
+	t.Run("Environment changes are seen by later decodes", func(t *testing.T) {
+		t.Setenv("SNAPSHOT_PORT", "80")
+		type Config struct {
+			Port int `env:"SNAPSHOT_PORT"`
+		}
+
+		first, second := &Config{}, &Config{}
+		assert.Nil(t, Unmarshal(first))
+		t.Setenv("SNAPSHOT_PORT", "81")
+		assert.Nil(t, Unmarshal(second))
+
+		assert.Equal(t, 80, first.Port)
+		assert.Equal(t, 81, second.Port)
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	type Database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	type Config struct {
+		Name     string            `env:"NAME"`
+		Debug    bool              `env:"DEBUG"`
+		Timeout  time.Duration     `env:"TIMEOUT"`
+		Hosts    []string          `env:"HOSTS"`
+		Labels   map[string]string `env:"LABELS"`
+		Database Database          `envPrefix:"DB_"`
+	}
+	decoder := NewDecoder(WithSource(MapSource{
+		"NAME": "bench", "DEBUG": "true", "TIMEOUT": "5s", "HOSTS": "a,b,c",
+		"LABELS_APP": "goenv", "DB_HOST": "localhost", "DB_PORT": "5432",
+	}))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var cfg Config
+		if err := decoder.Decode(&cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUnmarshal_Required(t *testing.T) {
+	type Database struct {
+		Host string `env:"DB_HOST,required"`
+		Port int    `env:"DB_PORT,required" defaultEnv:"5432"`
+	}
+	type Config struct {
+		Name     string `env:"NAME,required"`
+		Database Database
+		Labels   map[string]string `env:"LABELS,required"`
+	}
+
+	t.Run("All present", func(t *testing.T) {
+		source := MapSource{"NAME": "", "DB_HOST": "localhost", "LABELS_APP": "goenv"}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{
+			Database: Database{Host: "localhost", Port: 5432},
+			Labels:   map[string]string{"app": "goenv"},
+		}, *actualStruct)
+	})
+
+	t.Run("Missing variable", func(t *testing.T) {
+		source := MapSource{"NAME": "goenv", "LABELS_APP": "goenv"}
+
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "Database.Host", Var: "DB_HOST"}, err)
+		assert.EqualError(t, err, "required environment variable DB_HOST is not set (field Database.Host)")
+	})
+
+	t.Run("Missing map variables", func(t *testing.T) {
+		source := MapSource{"NAME": "goenv", "DB_HOST": "localhost"}
+
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "Labels", Var: "LABELS"}, err)
+	})
+}
+
+func TestUnmarshal_CollectErrors(t *testing.T) {
+	type Database struct {
+		Host string `env:"DB_HOST,required"`
+		Port int    `env:"DB_PORT"`
+	}
+	type Config struct {
+		Name     string `env:"NAME,required"`
+		Debug    bool   `env:"DEBUG"`
+		Database Database
+	}
+	source := MapSource{"DEBUG": "maybe", "DB_PORT": "abc"}
+
+	t.Run("Stops at first error by default", func(t *testing.T) {
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "Name", Var: "NAME"}, err)
+	})
+
+	t.Run("Collects every error", func(t *testing.T) {
+		err := NewDecoder(WithSource(source), WithCollectErrors()).Decode(&Config{})
+
+		var multiErr MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 4)
+		assert.Equal(t, MissingRequiredError{Field: "Name", Var: "NAME"}, multiErr.Errors[0])
+		assert.Equal(t, MissingRequiredError{Field: "Database.Host", Var: "DB_HOST"}, multiErr.Errors[2])
+
+		var parseErr ParseError
+		assert.True(t, errors.As(multiErr.Errors[1], &parseErr))
+		assert.Equal(t, "Debug", parseErr.Field)
+		assert.Equal(t, "DEBUG", parseErr.Var)
+		assert.True(t, errors.As(multiErr.Errors[3], &parseErr))
+		assert.Equal(t, "Database.Port", parseErr.Field)
+
+		var missingErr MissingRequiredError
+		assert.True(t, errors.As(err, &missingErr))
+		assert.Equal(t, "NAME", missingErr.Var)
+		assert.Contains(t, err.Error(), "4 errors occurred while decoding environment variables:")
+	})
+
+	t.Run("No error when every field is valid", func(t *testing.T) {
+		source := MapSource{"NAME": "goenv", "DB_HOST": "localhost"}
+
+		err := NewDecoder(WithSource(source), WithCollectErrors()).Decode(&Config{})
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestUnmarshal_ParseError(t *testing.T) {
+	type Database struct {
+		Port int `env:"DB_PORT"`
+	}
+	type Config struct {
+		Database Database
+		Ports    []uint16           `env:"PORTS"`
+		Weights  map[string]int     `env:"WEIGHTS"`
+		Options  map[string]*string `env:"OPTIONS"`
+	}
+
+	t.Run("Scalar", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"DB_PORT": "abc"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Database.Port", parseErr.Field)
+		assert.Equal(t, "DB_PORT", parseErr.Var)
+		assert.Equal(t, "abc", parseErr.Value)
+		assert.Equal(t, reflect.TypeOf(0), parseErr.Type)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.EqualError(t, err, `cannot parse value "abc" of DB_PORT as int (field Database.Port): strconv.ParseInt: parsing "abc": invalid syntax`)
+	})
+
+	t.Run("Slice element", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"PORTS": "80,70000"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Ports[1]", parseErr.Field)
+		assert.Equal(t, "70000", parseErr.Value)
+		assert.ErrorIs(t, err, strconv.ErrRange)
+	})
+
+	t.Run("Map value", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"WEIGHTS_PRIMARY_DB": "heavy"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Weights[primaryDb]", parseErr.Field)
+		assert.Equal(t, "WEIGHTS_PRIMARY_DB", parseErr.Var)
+	})
+
+	t.Run("Redacted value", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"DB_PORT": "s3cr3t"}), WithRedactValues()).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.True(t, parseErr.Redacted)
+		assert.Empty(t, parseErr.Value)
+		assert.NotContains(t, err.Error(), "s3cr3t")
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+	})
+
+	t.Run("No parser found is not a parse error", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"OPTIONS_A": "1"})).Decode(&Config{})
+
+		assert.Equal(t, NoParserFoundError{"*string"}, err)
+	})
+}
+
+type upperString string
+
+func (s *upperString) UnmarshalEnvValue(value string) error {
+	if value == "" {
+		return errors.New("empty value")
+	}
+	*s = upperString(strings.ToUpper(value))
+	return nil
+}
+
+type binaryPair [2]byte
+
+func (p *binaryPair) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("expected 2 bytes")
+	}
+	copy(p[:], data)
+	return nil
+}
+
+type jsonSettings struct {
+	Retries int `json:"retries"`
+}
+
+func (s *jsonSettings) UnmarshalJSON(data []byte) error {
+	type plain jsonSettings
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+type jsonID struct {
+	value string
+}
+
+func (id jsonID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.value)
+}
+
+func (id *jsonID) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &id.value)
+}
+
+func TestUnmarshal_Unmarshalers(t *testing.T) {
+	type Config struct {
+		Name     upperString            `env:"NAME"`
+		IP       net.IP                 `env:"IP"`
+		IPs      []net.IP               `env:"IPS"`
+		Hosts    map[string]net.IP      `env:"HOSTS"`
+		Big      big.Int                `env:"BIG"`
+		BigPtrs  []*big.Int             `env:"BIG_PTRS"`
+		Pair     binaryPair             `env:"PAIR"`
+		Settings jsonSettings           `env:"SETTINGS"`
+		Names    map[string]upperString `env:"NAMES"`
+	}
+	source := MapSource{
+		"NAME":           "goenv",
+		"IP":             "10.0.0.1",
+		"IPS":            "10.0.0.2,::1",
+		"HOSTS_PRIMARY":  "10.0.0.3",
+		"BIG":            "123456789012345678901234567890",
+		"BIG_PTRS":       "1,2",
+		"PAIR":           "ab",
+		"SETTINGS":       `{"retries":3}`,
+		"NAMES_FIRST":    "a",
+		"NAMES_SECOND_X": "b",
+	}
+
+	actualStruct := &Config{}
+	err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+	assert.Nil(t, err)
+	assert.Equal(t, upperString("GOENV"), actualStruct.Name)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), actualStruct.IP)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("::1")}, actualStruct.IPs)
+	assert.Equal(t, map[string]net.IP{"primary": net.ParseIP("10.0.0.3")}, actualStruct.Hosts)
+	assert.Equal(t, "123456789012345678901234567890", actualStruct.Big.String())
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, actualStruct.BigPtrs)
+	assert.Equal(t, binaryPair{'a', 'b'}, actualStruct.Pair)
+	assert.Equal(t, jsonSettings{Retries: 3}, actualStruct.Settings)
+	assert.Equal(t, map[string]upperString{"first": "A", "secondX": "B"}, actualStruct.Names)
+
+	t.Run("Unmarshaler error", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"IP": "not-an-ip"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "IP", parseErr.Field)
+		assert.Equal(t, "not-an-ip", parseErr.Value)
+	})
+
+	t.Run("JSON unmarshaler with plain string", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"SETTINGS": "plain"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Settings", parseErr.Field)
+	})
+
+	t.Run("String-backed JSON unmarshaler", func(t *testing.T) {
+		type Config struct {
+			ID  jsonID   `env:"ID"`
+			IDs []jsonID `env:"IDS"`
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(MapSource{"ID": "123", "IDS": "true,{}"})).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, jsonID{"123"}, actualStruct.ID)
+		assert.Equal(t, []jsonID{{"true"}, {"{}"}}, actualStruct.IDs)
+	})
+}
+
+func TestUnmarshal_Pointer(t *testing.T) {
+	type TLS struct {
+		Cert string `env:"TLS_CERT,required"`
+		Key  string `env:"TLS_KEY"`
+	}
+	type Config struct {
+		Port    *int      `env:"PORT"`
+		Name    *string   `env:"NAME"`
+		Debug   *bool     `env:"DEBUG" defaultEnv:"false"`
+		Hosts   *[]string `env:"HOSTS"`
+		Retries **uint    `env:"RETRIES"`
+		TLS     *TLS
+	}
+
+	t.Run("Nil when unset", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(MapSource{})).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Nil(t, actualStruct.Port)
+		assert.Nil(t, actualStruct.Name)
+		assert.Nil(t, actualStruct.Hosts)
+		assert.Nil(t, actualStruct.Retries)
+		assert.Nil(t, actualStruct.TLS)
+		assert.NotNil(t, actualStruct.Debug)
+		assert.False(t, *actualStruct.Debug)
+	})
+
+	t.Run("Allocated when set", func(t *testing.T) {
+		source := MapSource{"PORT": "0", "NAME": "", "HOSTS": "a,b", "RETRIES": "3", "TLS_CERT": "cert.pem"}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, *actualStruct.Port)
+		assert.Equal(t, "", *actualStruct.Name)
+		assert.Equal(t, []string{"a", "b"}, *actualStruct.Hosts)
+		assert.Equal(t, uint(3), **actualStruct.Retries)
+		assert.Equal(t, &TLS{Cert: "cert.pem"}, actualStruct.TLS)
+	})
+
+	t.Run("Nested struct errors apply once configured", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"TLS_KEY": "key.pem"})).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "TLS.Cert", Var: "TLS_CERT"}, err)
+	})
+
+	t.Run("Always allocate structs", func(t *testing.T) {
+		type Optional struct {
+			Key string `env:"TLS_KEY"`
+		}
+		actualStruct := &struct {
+			Optional *Optional
+		}{}
+		err := NewDecoder(WithSource(MapSource{}), WithAlwaysAllocateStructs()).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &Optional{}, actualStruct.Optional)
+	})
+
+	t.Run("Existing pointer is decoded in place", func(t *testing.T) {
+		tls := &TLS{Key: "preset.pem"}
+		actualStruct := &Config{TLS: tls}
+		err := NewDecoder(WithSource(MapSource{"TLS_CERT": "cert.pem"})).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Same(t, tls, actualStruct.TLS)
+		assert.Equal(t, TLS{Cert: "cert.pem", Key: "preset.pem"}, *tls)
+	})
+
+	t.Run("Pointer to type with built-in parser", func(t *testing.T) {
+		type Config struct {
+			When  *time.Time   `env:"WHEN" envLayout:"DateOnly"`
+			Dates []*time.Time `env:"DATES" envLayout:"DateOnly"`
+			Addr  *netip.Addr  `env:"ADDR"`
+		}
+		source := MapSource{"WHEN": "2024-01-02", "DATES": "2024-01-02,2024-01-03", "ADDR": "10.0.0.1"}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), *actualStruct.When)
+		assert.Len(t, actualStruct.Dates, 2)
+		assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), *actualStruct.Dates[1])
+		assert.Equal(t, netip.MustParseAddr("10.0.0.1"), *actualStruct.Addr)
+
+		err = NewDecoder(WithSource(MapSource{"ADDR": "10.0.0"})).Decode(&Config{})
+		assert.ErrorIs(t, err, InvalidIPError)
+	})
+
+	t.Run("Recursive types", func(t *testing.T) {
+		type Node struct {
+			Name string `env:"NAME"`
+			Next *Node
+		}
+		type Queue struct {
+			Name  string `env:"NAME"`
+			Queue *list.List
+		}
+		source := MapSource{"NAME": "head"}
+
+		node := &Node{}
+		err := NewDecoder(WithSource(source)).Decode(node)
+		assert.Nil(t, err)
+		assert.Equal(t, &Node{Name: "head"}, node)
+
+		node = &Node{}
+		err = NewDecoder(WithSource(source), WithAlwaysAllocateStructs()).Decode(node)
+		assert.Nil(t, err)
+		assert.Equal(t, &Node{Name: "head"}, node)
+
+		queue := &Queue{}
+		err = NewDecoder(WithSource(source)).Decode(queue)
+		assert.Nil(t, err)
+		assert.Equal(t, &Queue{Name: "head"}, queue)
+	})
+
+	t.Run("Parse error", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"PORT": "http"})).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Port", parseErr.Field)
+	})
+}
+
+func TestUnmarshal_Prefix(t *testing.T) {
+	type DBConfig struct {
+		Host   string            `env:"HOST"`
+		Port   int               `env:"PORT,required"`
+		Params map[string]string `env:"PARAMS"`
+	}
+	type Cluster struct {
+		Primary DBConfig  `envPrefix:"PRIMARY_"`
+		Replica *DBConfig `envPrefix:"REPLICA_"`
+	}
+	type Config struct {
+		Cluster Cluster `envPrefix:"DB_"`
+	}
+
+	t.Run("Prefix tag", func(t *testing.T) {
+		source := MapSource{
+			"DB_PRIMARY_HOST":           "primary",
+			"DB_PRIMARY_PORT":           "5432",
+			"DB_PRIMARY_PARAMS_SSLMODE": "require",
+			"DB_REPLICA_HOST":           "replica",
+			"DB_REPLICA_PORT":           "5433",
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, DBConfig{Host: "primary", Port: 5432, Params: map[string]string{"sslmode": "require"}}, actualStruct.Cluster.Primary)
+		assert.Equal(t, &DBConfig{Host: "replica", Port: 5433, Params: map[string]string{}}, actualStruct.Cluster.Replica)
+	})
+
+	t.Run("Missing variable is reported with its prefix", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{"DB_PRIMARY_HOST": "primary"})).Decode(&Config{})
+
+		assert.Equal(t, MissingRequiredError{Field: "Cluster.Primary.Port", Var: "DB_PRIMARY_PORT"}, err)
+	})
+
+	t.Run("Root prefix and auto prefix", func(t *testing.T) {
+		type Server struct {
+			Port int `env:"PORT"`
+		}
+		type AppConfig struct {
+			HTTPServer Server
+			Admin      Server `envPrefix:"ADM_"`
+			Server
+			Unprefixed Server `envPrefix:""`
+		}
+		source := MapSource{
+			"APP_HTTP_SERVER_PORT": "80",
+			"APP_ADM_PORT":         "81",
+			"APP_PORT":             "82",
+		}
+
+		actualStruct := &AppConfig{}
+		err := NewDecoder(WithSource(source), WithPrefix("APP_"), WithAutoPrefix()).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, AppConfig{
+			HTTPServer: Server{Port: 80},
+			Admin:      Server{Port: 81},
+			Server:     Server{Port: 82},
+			Unprefixed: Server{Port: 82},
+		}, *actualStruct)
+	})
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Host":           "HOST",
+		"MaxIdleConns":   "MAX_IDLE_CONNS",
+		"DBMaxIdleConns": "DB_MAX_IDLE_CONNS",
+		"HTTPServer":     "HTTP_SERVER",
+		"UserID":         "USER_ID",
+		"S3Bucket":       "S3_BUCKET",
+		"Port2":          "PORT2",
+		"already_snake":  "ALREADY_SNAKE",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, UpperSnakeCase(input), input)
+	}
+}
+
+func TestUnmarshal_Naming(t *testing.T) {
+	type Database struct {
+		MaxIdleConns int
+		URL          string `env:"DATABASE_URL"`
+		Password     string `env:",required"`
+	}
+	type Config struct {
+		HTTPPort int
+		Debug    bool     `env:"-"`
+		Ignored  Database `env:"-"`
+		Database Database
+		internal string
+	}
+	source := MapSource{
+		"HTTP_PORT":      "8080",
+		"DEBUG":          "true",
+		"MAX_IDLE_CONNS": "5",
+		"DATABASE_URL":   "postgres://",
+		"PASSWORD":       "secret",
+		"INTERNAL":       "value",
+	}
+
+	t.Run("Derived names", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source), WithNaming(UpperSnakeCase)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{
+			HTTPPort: 8080,
+			Database: Database{MaxIdleConns: 5, URL: "postgres://", Password: "secret"},
+		}, *actualStruct)
+	})
+
+	t.Run("Derived names with auto prefix", func(t *testing.T) {
+		source := MapSource{"HTTPPORT": "80", "DATABASE_MAXIDLECONNS": "7", "DATABASE_PASSWORD": "secret"}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source), WithNaming(UpperCase), WithAutoPrefix()).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 80, actualStruct.HTTPPort)
+		assert.Equal(t, 7, actualStruct.Database.MaxIdleConns)
+	})
+
+	t.Run("Untagged fields are skipped by default", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Database: Database{URL: "postgres://"}}, *actualStruct)
+	})
+}
+
+type selfDecoding struct {
+	Name   string `env:"NAME"`
+	source Source
+}
+
+func (s *selfDecoding) UnmarshalEnv(src Source) error {
+	s.source = src
+	s.Name, _ = src.Lookup("GENERATED_NAME")
+	return nil
+}
+
+func TestUnmarshal_StructUnmarshaler(t *testing.T) {
+	t.Setenv("NAME", "reflection")
+	t.Setenv("GENERATED_NAME", "generated")
+
+	t.Run("Unmarshal uses UnmarshalEnv", func(t *testing.T) {
+		actualStruct := &selfDecoding{}
+		err := Unmarshal(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "generated", actualStruct.Name)
+		assert.Equal(t, OSSource{}, actualStruct.source)
+	})
+
+	t.Run("Decoders decode by reflection", func(t *testing.T) {
+		actualStruct := &selfDecoding{}
+		err := NewDecoder().Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "reflection", actualStruct.Name)
+	})
+
+	t.Run("Nil pointer", func(t *testing.T) {
+		var actualStruct *selfDecoding
+		err := Unmarshal(actualStruct)
+
+		assert.IsType(t, NotStructPtrError{}, err)
+	})
+}
+
+func TestUnmarshalField(t *testing.T) {
+	source := MapSource{"DB_TIMEOUT": "3", "DB_LABELS_TEAM": "core"}
+
+	var timeout time.Duration
+	err := UnmarshalField(source, &timeout, "Database.Timeout", `env:"TIMEOUT"`, "DB_")
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Second, timeout)
+
+	var labels map[string]string
+	err = UnmarshalField(source, &labels, "Database.Labels", `env:"LABELS"`, "DB_")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "core"}, labels)
+
+	var port int
+	err = UnmarshalField(MapSource{}, &port, "Database.Port", `env:"PORT,required"`, "DB_")
+	assert.Equal(t, MissingRequiredError{Field: "Database.Port", Var: "DB_PORT"}, err)
+
+	t.Run("Plans are cached", func(t *testing.T) {
+		fieldType := reflect.StructField{Name: "Labels", Type: reflect.TypeOf(labels), Tag: `env:"LABELS"`}
+		options := defaultOptions()
+		options.Prefix = "DB_"
+
+		plan := generatedFieldPlan(fieldType, "Database.Labels", options)
+		assert.Same(t, plan, generatedFieldPlan(fieldType, "Database.Labels", options))
+		assert.NotSame(t, plan, generatedFieldPlan(fieldType, "Database.Labels", defaultOptions()))
+	})
+}
@@ -0,0 +1,255 @@
+// Package envlint defines an analyzer reporting mistakes in the tags of structs passed to goenv.Unmarshal,
+// which would otherwise only be found when the struct is decoded:
+//   - default values which cannot be parsed into the type of their field
+//   - environment variables read by several fields, e.g. through nested structs
+//   - map fields whose keys are not strings
+//   - fields whose type cannot be parsed from an environment variable
+//
+// The structs are checked against the default options of Unmarshal. Custom parsers registered with
+// goenv.WithParser are not visible to the analyzer, which is why structs given to a Decoder are not checked.
+//
+// The analyzer can be run with the goenvlint command, standalone or through go vet:
+//
+//	go vet -vettool=$(which goenvlint) ./...
+package envlint
+
+import (
+	"fmt"
+	"github.com/ilhamtubagus/goenv/internal/envtypes"
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const doc = `check the tags of structs passed to goenv.Unmarshal
+
+The envlint analyzer reports default values which cannot be parsed into their field,
+environment variables read by several fields, map fields with non-string keys and
+fields whose type cannot be parsed from an environment variable.`
+
+// Analyzer reports mistakes in the tags of structs passed to goenv.Unmarshal.
+var Analyzer = &analysis.Analyzer{
+	Name:     "envlint",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const goenvPath = "github.com/ilhamtubagus/goenv"
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	checker := &checker{pass: pass, reported: make(map[string]bool), decoding: make(map[*types.Named]bool)}
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != goenvPath || fn.Name() != "Unmarshal" || len(call.Args) != 1 {
+			return
+		}
+
+		ptr, ok := pass.TypesInfo.TypeOf(call.Args[0]).Underlying().(*types.Pointer)
+		if !ok {
+			return
+		}
+		if isStruct(ptr.Elem()) {
+			checker.call = call.Args[0]
+			checker.checkStruct(ptr.Elem(), "", "", make(map[string]string))
+		}
+	})
+
+	return nil, nil
+}
+
+type checker struct {
+	pass *analysis.Pass
+
+	// call is the argument of the Unmarshal call being checked, where problems in fields declared in
+	// other packages are reported.
+	call ast.Expr
+
+	// reported holds the problems already reported, as a struct may be decoded several times.
+	reported map[string]bool
+
+	// decoding holds the named struct types being checked. Like goenv, the checker does not check them again
+	// inside themselves, which would never end for recursive types.
+	decoding map[*types.Named]bool
+}
+
+// checkStruct checks the fields of a struct type, mirroring how goenv decodes it. The names of the variables
+// read so far are mapped to the path of the field reading them in names.
+func (c *checker) checkStruct(typ types.Type, path, prefix string, names map[string]string) {
+	if named, ok := typ.(*types.Named); ok {
+		named = named.Origin()
+		if c.decoding[named] {
+			return
+		}
+		c.decoding[named] = true
+		defer delete(c.decoding, named)
+	}
+
+	st := typ.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		c.checkField(st.Field(i), reflect.StructTag(st.Tag(i)), envtypes.JoinPath(path, st.Field(i).Name()), prefix, names)
+	}
+}
+
+func (c *checker) checkField(field *types.Var, tag reflect.StructTag, path, prefix string, names map[string]string) {
+	if tag.Get(envtypes.TagName) == envtypes.IgnoredTag {
+		return
+	}
+
+	typ := field.Type()
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok && !envtypes.HasParser(typ) && !envtypes.HasParser(ptr.Elem()) &&
+		isStruct(ptr.Elem()) {
+		typ = ptr.Elem()
+	}
+	if isStruct(typ) && !envtypes.HasParser(typ) {
+		c.checkStruct(typ, path, prefix+tag.Get(envtypes.PrefixTagName), names)
+		return
+	}
+
+	envName, _, _ := strings.Cut(tag.Get(envtypes.TagName), ",")
+	if envName == "" {
+		return
+	}
+	envName = prefix + envName
+	if other, ok := names[envName]; ok {
+		c.reportf(field, "environment variable %s of field %s is also read by field %s", envName, path, other)
+	} else {
+		names[envName] = path
+	}
+
+	// pointers are allocated and their element parsed, unless the pointer type itself has a parser
+	typ = field.Type()
+	for ptr, ok := typ.Underlying().(*types.Pointer); ok && !envtypes.HasParser(typ); ptr, ok = typ.Underlying().(*types.Pointer) {
+		typ = ptr.Elem()
+	}
+	defaultValue, hasDefault := tag.Lookup(envtypes.DefaultTagName)
+	hasDefault = hasDefault && defaultValue != ""
+
+	if !envtypes.HasParser(typ) {
+		switch u := typ.Underlying().(type) {
+		case *types.Slice:
+			elem := u.Elem()
+			if ptr, ok := elem.Underlying().(*types.Pointer); ok && !envtypes.HasParser(elem) {
+				elem = ptr.Elem()
+			}
+			if !c.checkType(field, path, elem) || !hasDefault {
+				return
+			}
+			separator := tag.Get(envtypes.SeparatorTagName)
+			if separator == "" {
+				separator = envtypes.DefaultSeparator
+			}
+			for _, part := range strings.Split(defaultValue, separator) {
+				c.checkDefault(field, path, elem, part)
+			}
+			return
+		case *types.Map:
+			if key, ok := u.Key().Underlying().(*types.Basic); !ok || key.Kind() != types.String {
+				c.reportf(field, "map field %s has keys of type %s, only string keys are supported", path, u.Key())
+				return
+			}
+			c.checkType(field, path, u.Elem())
+			return
+		}
+	}
+
+	if c.checkType(field, path, typ) && hasDefault {
+		c.checkDefault(field, path, typ, defaultValue)
+	}
+}
+
+// checkType reports whether values of typ can be parsed, and reports the field when they cannot.
+func (c *checker) checkType(field *types.Var, path string, typ types.Type) bool {
+	if envtypes.HasParser(typ) || envtypes.IsParsedByKind(typ) {
+		return true
+	}
+
+	c.reportf(field, "field %s has type %s, which cannot be parsed from an environment variable", path, typ)
+	return false
+}
+
+// checkDefault reports the field when its default value cannot be parsed into typ. Only values parsed
+// by their kind, and durations, are checked.
+func (c *checker) checkDefault(field *types.Var, path string, typ types.Type, value string) {
+	var err error
+	if types.TypeString(typ, nil) == "time.Duration" {
+		if _, intErr := strconv.ParseInt(value, 10, 64); intErr != nil {
+			_, err = time.ParseDuration(value)
+		}
+	} else if envtypes.HasParser(typ) {
+		return
+	} else if basic, ok := typ.Underlying().(*types.Basic); ok {
+		err = parseBasic(basic, value)
+	}
+
+	if err != nil {
+		c.reportf(field, "default value %q of field %s cannot be parsed as %s: %v", value, path, typ, err)
+	}
+}
+
+// parseBasic parses a value like the default parsers of goenv, which read int and uint values on 32 bits.
+func parseBasic(basic *types.Basic, value string) error {
+	var err error
+	switch basic.Kind() {
+	case types.Bool:
+		_, err = strconv.ParseBool(value)
+	case types.Int, types.Int32:
+		_, err = strconv.ParseInt(value, 10, 32)
+	case types.Int8:
+		_, err = strconv.ParseInt(value, 10, 8)
+	case types.Int16:
+		_, err = strconv.ParseInt(value, 10, 16)
+	case types.Int64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case types.Uint, types.Uint32:
+		_, err = strconv.ParseUint(value, 10, 32)
+	case types.Uint8:
+		_, err = strconv.ParseUint(value, 10, 8)
+	case types.Uint16:
+		_, err = strconv.ParseUint(value, 10, 16)
+	case types.Uint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case types.Float32:
+		_, err = strconv.ParseFloat(value, 32)
+	case types.Float64:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	if numErr, ok := err.(*strconv.NumError); ok {
+		err = numErr.Err
+	}
+
+	return err
+}
+
+// reportf reports a problem at the field when it is declared in the analysed package, or else at the
+// argument of the Unmarshal call.
+func (c *checker) reportf(field *types.Var, format string, args ...interface{}) {
+	pos := c.call.Pos()
+	if field.Pkg() == c.pass.Pkg {
+		pos = field.Pos()
+	}
+
+	message := fmt.Sprintf(format, args...)
+	key := fmt.Sprintf("%d:%s", pos, message)
+	if c.reported[key] {
+		return
+	}
+	c.reported[key] = true
+
+	c.pass.Reportf(pos, "%s", message)
+}
+
+func isStruct(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
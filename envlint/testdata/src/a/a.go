@@ -0,0 +1,76 @@
+package a
+
+import (
+	"b"
+	"container/list"
+	"github.com/ilhamtubagus/goenv"
+	"net/url"
+	"time"
+)
+
+type Level int
+
+func (l *Level) UnmarshalEnvValue(value string) error {
+	return nil
+}
+
+type Database struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT" defaultEnv:"5432"`
+}
+
+type Config struct {
+	Name     string            `env:"NAME,required"`
+	Port     int               `env:"PORT" defaultEnv:"abc"`  // want `default value "abc" of field Port cannot be parsed as int: invalid syntax`
+	Small    int8              `env:"SMALL" defaultEnv:"300"` // want `default value "300" of field Small cannot be parsed as int8: value out of range`
+	Debug    *bool             `env:"DEBUG" defaultEnv:"yes"` // want `default value "yes" of field Debug cannot be parsed as bool: invalid syntax`
+	Timeout  time.Duration     `env:"TIMEOUT" defaultEnv:"10"`
+	Interval time.Duration     `env:"INTERVAL" defaultEnv:"often"`              // want `default value "often" of field Interval cannot be parsed as time.Duration: .*`
+	Ports    []int             `env:"PORTS" defaultEnv:"80;x" envSeparator:";"` // want `default value "x" of field Ports cannot be parsed as int: invalid syntax`
+	Level    Level             `env:"LEVEL" defaultEnv:"anything"`
+	Endpoint *url.URL          `env:"ENDPOINT" defaultEnv:"http://localhost"`
+	Since    *time.Time        `env:"SINCE" envLayout:"DateOnly" defaultEnv:"2024-01-02"`
+	Labels   map[string]string `env:"LABELS"`
+	Password goenv.Secret[int] `env:"PASSWORD" defaultEnv:"1234"`
+	Limits   map[int]string    `env:"LIMITS"`  // want `map field Limits has keys of type int, only string keys are supported`
+	Values   map[string]*int   `env:"VALUES"`  // want `field Values has type \*int, which cannot be parsed from an environment variable`
+	Ratio    complex64         `env:"RATIO"`   // want `field Ratio has type complex64, which cannot be parsed from an environment variable`
+	Handler  func()            `env:"HANDLER"` // want `field Handler has type func\(\), which cannot be parsed from an environment variable`
+	Ignored  chan int          `env:"-"`
+	Untagged chan int
+
+	Database Database  `envPrefix:"DB_"`
+	Replica  *Database `envPrefix:"REPLICA_"`
+	Legacy   struct {
+		Host string `env:"DB_HOST"` // want `environment variable DB_HOST of field Legacy.Host is also read by field Database.Host`
+	}
+}
+
+type Other struct {
+	Remote b.Remote
+	Port   int `env:"PORT" defaultEnv:"abc"` // want `default value "abc" of field Port cannot be parsed as int: invalid syntax`
+}
+
+type Node struct {
+	Name  string `env:"NAME"`
+	Port  int    `env:"NODE_PORT" defaultEnv:"x"` // want `default value "x" of field Port cannot be parsed as int: invalid syntax`
+	Next  *Node
+	Queue *list.List
+}
+
+type Decoded struct {
+	Port int `env:"PORT" defaultEnv:"abc"`
+}
+
+func load() {
+	var cfg Config
+	_ = goenv.Unmarshal(&cfg)
+	_ = goenv.Unmarshal(&cfg)
+
+	_ = goenv.Unmarshal(&Node{})
+
+	_ = goenv.Unmarshal(&Other{}) // want `field Remote.Channel has type chan int, which cannot be parsed from an environment variable`
+
+	var decoder goenv.Decoder
+	_ = decoder.Decode(&Decoded{})
+}
@@ -0,0 +1,5 @@
+package b
+
+type Remote struct {
+	Channel chan int `env:"CHANNEL"`
+}
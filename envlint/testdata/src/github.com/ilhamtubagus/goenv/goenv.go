@@ -0,0 +1,15 @@
+package goenv
+
+func Unmarshal(v interface{}) error {
+	return nil
+}
+
+type Decoder struct{}
+
+func (d *Decoder) Decode(v interface{}) error {
+	return nil
+}
+
+type Secret[T any] struct {
+	value T
+}
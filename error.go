@@ -3,10 +3,23 @@ package goenv
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var InvalidMapKeyError = errors.New("map must have string keys")
 var InvalidEnvironmentVariableError = errors.New("environment format is invalid")
+var InvalidURLError = errors.New("invalid URL")
+var InvalidIPError = errors.New("invalid IP address")
+var InvalidCIDRError = errors.New("invalid CIDR prefix")
+var InvalidMACError = errors.New("invalid hardware address")
+var InvalidAddrPortError = errors.New("invalid address and port")
+var InvalidMailAddressError = errors.New("invalid mail address")
+var MultilineValueError = errors.New("multi-line values are not supported by this format")
+var FileTooLargeError = errors.New("file exceeds the maximum size")
+var NilElementError = errors.New("nil slice elements cannot be marshaled")
 
 // NotStructPtrError The error occurs when pass something that is not a pointer to a struct to Parse
 type NotStructPtrError struct {
@@ -24,3 +37,295 @@ type NoParserFoundError struct {
 func (e NoParserFoundError) Error() string {
 	return fmt.Sprintf("no parser found for type %s", e.fieldType)
 }
+
+type NoFormatterFoundError struct {
+	fieldType string
+}
+
+func (e NoFormatterFoundError) Error() string {
+	return fmt.Sprintf("no formatter found for type %s", e.fieldType)
+}
+
+// DotenvSyntaxError is returned when a dotenv file cannot be parsed.
+type DotenvSyntaxError struct {
+	// Filename is the name of the file being parsed, empty when reading from an io.Reader.
+	Filename string
+
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e DotenvSyntaxError) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Msg)
+}
+
+// MissingRequiredError occurs when the environment variable of a required field is not set and the field has no default value.
+type MissingRequiredError struct {
+	// Field is the dotted path of the field from the decoded struct, e.g. Database.Host.
+	Field string
+
+	// Var is the name of the missing environment variable.
+	Var string
+
+	// Condition tells why a conditionally required field is required, e.g. AUTH_MODE is "oidc".
+	// It is empty for fields tagged required.
+	Condition string
+}
+
+func (e MissingRequiredError) Error() string {
+	if e.Condition != "" {
+		return fmt.Sprintf("environment variable %s is required when %s (field %s)", e.Var, e.Condition, e.Field)
+	}
+
+	return fmt.Sprintf("required environment variable %s is not set (field %s)", e.Var, e.Field)
+}
+
+// FileConflictError occurs when both the environment variable of a field and its _FILE variable are set.
+type FileConflictError struct {
+	// Field is the dotted path of the field from the decoded struct.
+	Field string
+
+	// Var is the name of the environment variable of the field, e.g. DB_PASSWORD.
+	Var string
+
+	// FileVar is the name of the variable holding the name of the file, e.g. DB_PASSWORD_FILE.
+	FileVar string
+}
+
+func (e FileConflictError) Error() string {
+	return fmt.Sprintf("environment variables %s and %s are both set (field %s)", e.Var, e.FileVar, e.Field)
+}
+
+// ExcludedVariableError occurs when the environment variable of a field tagged excludedWith is set along with
+// one of the variables it excludes.
+type ExcludedVariableError struct {
+	// Field is the dotted path of the field from the decoded struct.
+	Field string
+
+	// Var is the name of the environment variable which must not be set.
+	Var string
+
+	// Condition tells why the variable must not be set, e.g. TOKEN is set.
+	Condition string
+}
+
+func (e ExcludedVariableError) Error() string {
+	return fmt.Sprintf("environment variable %s must not be set when %s (field %s)", e.Var, e.Condition, e.Field)
+}
+
+// ParseError occurs when the value of an environment variable cannot be converted into the type of its field,
+// or when a decoded struct is rejected by its Validate method, in which case Var and Value are empty.
+type ParseError struct {
+	// Field is the dotted path of the field from the decoded struct, e.g. Database.Port.
+	// Slice elements and map values are suffixed by their index or key, e.g. Ports[1].
+	// It is empty when the decoded struct itself is rejected by its Validate method.
+	Field string
+
+	// Var is the name of the environment variable holding the value.
+	Var string
+
+	// Value is the offending value. It is empty when Redacted is set.
+	Value string
+
+	// Redacted tells whether the value has been removed from the error, see WithRedactValues.
+	Redacted bool
+
+	// Type is the type the value was converted into.
+	Type reflect.Type
+
+	// Err is the underlying error returned by the parser.
+	Err error
+}
+
+func (e ParseError) Error() string {
+	if e.Var == "" {
+		if e.Field == "" {
+			return fmt.Sprintf("invalid %s: %v", e.Type, e.Err)
+		}
+		return fmt.Sprintf("invalid %s (field %s): %v", e.Type, e.Field, e.Err)
+	}
+	if e.Redacted {
+		return fmt.Sprintf("cannot parse value of %s as %s (field %s): %v", e.Var, e.Type, e.Field, e.Err)
+	}
+
+	return fmt.Sprintf("cannot parse value %q of %s as %s (field %s): %v", e.Value, e.Var, e.Type, e.Field, e.Err)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// redact removes the value from the error. The message of the underlying error, which often quotes the value
+// with escapes, is replaced by a fixed message telling what is wrong with the value, see redactedMessage.
+// The underlying error is still returned by Unwrap.
+func (e ParseError) redact() ParseError {
+	e.Err = redactedError{msg: redactedMessage(e.Err), err: e.Err}
+	e.Value = ""
+	e.Redacted = true
+
+	return e
+}
+
+// redactedCauses are the errors whose message tells what is wrong with a value without holding the value.
+var redactedCauses = []error{
+	strconv.ErrSyntax,
+	strconv.ErrRange,
+	InvalidURLError,
+	InvalidIPError,
+	InvalidCIDRError,
+	InvalidMACError,
+	InvalidAddrPortError,
+	InvalidMailAddressError,
+}
+
+// redactedMessage returns the message replacing the one of an error returned while parsing a redacted value:
+// the message of the first error of redactedCauses it wraps, or else a message telling the value is invalid.
+func redactedMessage(err error) string {
+	for _, cause := range redactedCauses {
+		if errors.Is(err, cause) {
+			return cause.Error()
+		}
+	}
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return "invalid time"
+	}
+
+	return "invalid value"
+}
+
+const redactedValue = "[REDACTED]"
+
+// redactedError replaces the message of an error whose message may contain a sensitive value.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// ValidationError occurs when a value does not satisfy a rule of the validate tag of its field.
+type ValidationError struct {
+	// Field is the dotted path of the field from the decoded struct, e.g. Server.Port.
+	// Slice elements and map values are suffixed by their index or key, e.g. Ports[1].
+	Field string
+
+	// Var is the name of the environment variable holding the value.
+	Var string
+
+	// Rule is the rule the value does not satisfy, as written in the tag, e.g. min=1.
+	Rule string
+
+	// Value is the offending value. It is empty when Redacted is set.
+	Value string
+
+	// Redacted tells whether the value has been removed from the error, see WithRedactValues.
+	Redacted bool
+}
+
+func (e ValidationError) Error() string {
+	if e.Redacted {
+		return fmt.Sprintf("value of %s does not satisfy %s (field %s)", e.Var, e.Rule, e.Field)
+	}
+
+	return fmt.Sprintf("value %q of %s does not satisfy %s (field %s)", e.Value, e.Var, e.Rule, e.Field)
+}
+
+// redact removes the value from the error.
+func (e ValidationError) redact() ValidationError {
+	e.Value = ""
+	e.Redacted = true
+
+	return e
+}
+
+// InvalidRuleError occurs when the validate tag of a field holds an unknown or malformed rule.
+type InvalidRuleError struct {
+	// Field is the dotted path of the field from the decoded struct.
+	Field string
+
+	// Rule is the offending rule, as written in the tag.
+	Rule string
+
+	// Err tells what is wrong with the rule.
+	Err error
+}
+
+func (e InvalidRuleError) Error() string {
+	return fmt.Sprintf("invalid validation rule %q (field %s): %v", e.Rule, e.Field, e.Err)
+}
+
+func (e InvalidRuleError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError wraps an error that occurred while decoding a field with the field path and variable name.
+type FieldError struct {
+	// Field is the dotted path of the field from the decoded struct, e.g. Database.Port.
+	Field string
+
+	// Var is the name of the environment variable of the field.
+	Var string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("field %s (%s): %v", e.Field, e.Var, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds every error found while decoding when errors are collected, see WithCollectErrors.
+// It supports errors.Is and errors.As on the errors it holds.
+type MultiError struct {
+	Errors []error
+}
+
+func (e MultiError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d errors occurred while decoding environment variables:", len(e.Errors))
+	for _, err := range e.Errors {
+		sb.WriteString("\n\t* ")
+		sb.WriteString(err.Error())
+	}
+
+	return sb.String()
+}
+
+func (e MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// withFieldContext wraps err in a FieldError unless it already names its field.
+func withFieldContext(err error, path, envName string) error {
+	switch err.(type) {
+	case MissingRequiredError, ExcludedVariableError, FileConflictError, ParseError, ValidationError, InvalidRuleError, FieldError:
+		return err
+	default:
+		return FieldError{Field: path, Var: envName, Err: err}
+	}
+}
+
+// appendErrors appends err to errs, flattening it first when it is a MultiError.
+func appendErrors(errs []error, err error) []error {
+	if multiErr, ok := err.(MultiError); ok {
+		return append(errs, multiErr.Errors...)
+	}
+
+	return append(errs, err)
+}
@@ -0,0 +1,68 @@
+package goenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// fileOption makes a field fall back to reading the file named by its _FILE variable, e.g. `env:"DB_PASSWORD,file"`.
+	fileOption = "file"
+
+	// noFileOption disables the _FILE fallback of a field when it is enabled for every field, see WithFileFallback.
+	noFileOption = "nofile"
+)
+
+// lookupFile returns the content of the file named by the _FILE variable of a field, e.g. DB_PASSWORD_FILE for
+// DB_PASSWORD. The file is only read when the variable of the field itself is not set: setting both is an error.
+//
+// Returns:
+//   - string: The content of the file, without its trailing newline.
+//   - bool: Whether the _FILE variable is set.
+//   - error: A FileConflictError if both variables are set, or a FieldError if the file cannot be read.
+func lookupFile(plan *fieldPlan, isPresent bool, options Options) (string, bool, error) {
+	filename, ok := options.Source.Lookup(plan.fileEnvName)
+	if !ok {
+		return "", false, nil
+	}
+	if isPresent {
+		return "", false, FileConflictError{Field: plan.path, Var: plan.envName, FileVar: plan.fileEnvName}
+	}
+
+	content, err := readSecretFile(filename, options.MaxFileSize)
+	if err != nil {
+		return "", false, FieldError{Field: plan.path, Var: plan.fileEnvName, Err: err}
+	}
+
+	return content, true, nil
+}
+
+// readSecretFile reads a file of at most maxSize bytes, removing its trailing newline.
+func readSecretFile(filename string, maxSize int64) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("%s: %w", filename, FileTooLargeError)
+	}
+
+	return trimTrailingNewline(string(data)), nil
+}
+
+// trimTrailingNewline removes the line ending, \n or \r\n, ending the content of a file.
+func trimTrailingNewline(content string) string {
+	if !strings.HasSuffix(content, "\n") {
+		return content
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(content, "\n"), "\r")
+}
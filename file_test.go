@@ -0,0 +1,148 @@
+package goenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretFile(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func TestUnmarshal_File(t *testing.T) {
+	type Config struct {
+		Password string `env:"DB_PASSWORD,file"`
+		Port     int    `env:"DB_PORT,file" defaultEnv:"5432"`
+		Host     string `env:"DB_HOST"`
+	}
+
+	t.Run("Read from file", func(t *testing.T) {
+		source := MapSource{
+			"DB_PASSWORD_FILE": writeSecretFile(t, "s3cret\n"),
+			"DB_PORT_FILE":     writeSecretFile(t, "6543\r\n"),
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Password: "s3cret", Port: 6543}, *actualStruct)
+	})
+
+	t.Run("Only the trailing newline is trimmed", func(t *testing.T) {
+		source := MapSource{"DB_PASSWORD_FILE": writeSecretFile(t, "line 1\nline 2\n\n")}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "line 1\nline 2\n", actualStruct.Password)
+	})
+
+	t.Run("Variable set", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(MapSource{"DB_PASSWORD": "plain"})).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{Password: "plain", Port: 5432}, *actualStruct)
+	})
+
+	t.Run("Both set", func(t *testing.T) {
+		source := MapSource{"DB_PASSWORD": "plain", "DB_PASSWORD_FILE": writeSecretFile(t, "s3cret")}
+
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		assert.Equal(t, FileConflictError{Field: "Password", Var: "DB_PASSWORD", FileVar: "DB_PASSWORD_FILE"}, err)
+		assert.EqualError(t, err, "environment variables DB_PASSWORD and DB_PASSWORD_FILE are both set (field Password)")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		source := MapSource{"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")}
+
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		var fieldErr FieldError
+		assert.True(t, errors.As(err, &fieldErr))
+		assert.Equal(t, "DB_PASSWORD_FILE", fieldErr.Var)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("File too large", func(t *testing.T) {
+		source := MapSource{"DB_PASSWORD_FILE": writeSecretFile(t, strings.Repeat("x", 11))}
+
+		err := NewDecoder(WithSource(source), WithMaxFileSize(10)).Decode(&Config{})
+
+		assert.True(t, errors.Is(err, FileTooLargeError))
+	})
+
+	t.Run("File of maximum size", func(t *testing.T) {
+		source := MapSource{"DB_PASSWORD_FILE": writeSecretFile(t, strings.Repeat("x", 10))}
+
+		err := NewDecoder(WithSource(source), WithMaxFileSize(10)).Decode(&Config{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Invalid file content", func(t *testing.T) {
+		source := MapSource{"DB_PORT_FILE": writeSecretFile(t, "http\n")}
+
+		err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "http", parseErr.Value)
+	})
+
+	t.Run("Fields without the file option", func(t *testing.T) {
+		source := MapSource{"DB_HOST_FILE": writeSecretFile(t, "db")}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "", actualStruct.Host)
+	})
+
+	t.Run("Fallback for every field", func(t *testing.T) {
+		type Config struct {
+			Host   string            `env:"HOST"`
+			Port   string            `env:"PORT,nofile"`
+			Labels map[string]string `env:"LABELS"`
+		}
+		source := MapSource{
+			"APP_HOST_SECRET":   writeSecretFile(t, "db"),
+			"APP_PORT_SECRET":   writeSecretFile(t, "5432"),
+			"APP_LABELS_SECRET": "team",
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(source), WithPrefix("APP_"), WithFileFallback(), WithFileSuffix("_SECRET")).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "db", actualStruct.Host)
+		assert.Equal(t, "", actualStruct.Port)
+		assert.Equal(t, map[string]string{"secret": "team"}, actualStruct.Labels)
+	})
+
+	t.Run("Required field", func(t *testing.T) {
+		type Config struct {
+			Password string `env:"DB_PASSWORD,required,file"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{"DB_PASSWORD_FILE": writeSecretFile(t, "s3cret")})).Decode(&Config{})
+		assert.Nil(t, err)
+
+		err = NewDecoder(WithSource(MapSource{})).Decode(&Config{})
+		assert.Equal(t, MissingRequiredError{Field: "Password", Var: "DB_PASSWORD"}, err)
+	})
+}
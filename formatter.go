@@ -0,0 +1,170 @@
+package goenv
+
+import (
+	"encoding"
+	"encoding/json"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatFunc is the counterpart of ParseFunc: it formats a value into the string stored in an environment variable.
+type FormatFunc func(interface{}) (string, error)
+
+// typeFormatFunc formats values of a specific type, with access to the tag of the field and the encoding options.
+type typeFormatFunc func(value interface{}, tag reflect.StructTag, options Options) (string, error)
+
+var (
+	defaultFormatter = map[reflect.Kind]FormatFunc{
+		reflect.Bool: func(v interface{}) (string, error) {
+			return strconv.FormatBool(reflect.ValueOf(v).Bool()), nil
+		},
+		reflect.String: func(v interface{}) (string, error) {
+			return reflect.ValueOf(v).String(), nil
+		},
+		reflect.Int:     formatInt,
+		reflect.Int8:    formatInt,
+		reflect.Int16:   formatInt,
+		reflect.Int32:   formatInt,
+		reflect.Int64:   formatInt,
+		reflect.Uint:    formatUint,
+		reflect.Uint8:   formatUint,
+		reflect.Uint16:  formatUint,
+		reflect.Uint32:  formatUint,
+		reflect.Uint64:  formatUint,
+		reflect.Float32: formatFloat,
+		reflect.Float64: formatFloat,
+	}
+
+	// defaultTypeFormatter holds the built-in formatters of the types in defaultTypeParser.
+	defaultTypeFormatter = map[reflect.Type]typeFormatFunc{
+		reflect.TypeOf(time.Duration(0)):      formatStringer,
+		reflect.TypeOf(time.Time{}):           formatTime,
+		reflect.TypeOf((*time.Location)(nil)): formatStringer,
+		reflect.TypeOf(url.URL{}):             formatPointerStringer,
+		reflect.TypeOf((*url.URL)(nil)):       formatStringer,
+		reflect.TypeOf(net.IP{}):              formatStringer,
+		reflect.TypeOf(net.IPNet{}):           formatPointerStringer,
+		reflect.TypeOf((*net.IPNet)(nil)):     formatStringer,
+		reflect.TypeOf(net.HardwareAddr{}):    formatStringer,
+		reflect.TypeOf(netip.Addr{}):          formatStringer,
+		reflect.TypeOf(netip.Prefix{}):        formatStringer,
+		reflect.TypeOf(netip.AddrPort{}):      formatStringer,
+		reflect.TypeOf(mail.Address{}):        formatPointerStringer,
+		reflect.TypeOf((*mail.Address)(nil)):  formatStringer,
+	}
+)
+
+func formatInt(v interface{}) (string, error) {
+	return strconv.FormatInt(reflect.ValueOf(v).Int(), 10), nil
+}
+
+func formatUint(v interface{}) (string, error) {
+	return strconv.FormatUint(reflect.ValueOf(v).Uint(), 10), nil
+}
+
+func formatFloat(v interface{}) (string, error) {
+	value := reflect.ValueOf(v)
+	return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits()), nil
+}
+
+type stringer interface {
+	String() string
+}
+
+func formatStringer(value interface{}, _ reflect.StructTag, _ Options) (string, error) {
+	return value.(stringer).String(), nil
+}
+
+// formatPointerStringer formats values whose String method has a pointer receiver.
+func formatPointerStringer(value interface{}, tag reflect.StructTag, options Options) (string, error) {
+	ptr := reflect.New(reflect.TypeOf(value))
+	ptr.Elem().Set(reflect.ValueOf(value))
+
+	return formatStringer(ptr.Interface(), tag, options)
+}
+
+// formatTime formats a time with the layout given in the layout tag of the field, see parseTime.
+// Without a layout, times are formatted as RFC 3339 with fractional seconds, which parseTime accepts.
+func formatTime(value interface{}, tag reflect.StructTag, options Options) (string, error) {
+	t := value.(time.Time)
+	layout := tag.Get(options.LayoutTagName)
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+
+	switch strings.ToLower(layout) {
+	case unixLayout:
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case unixMilliLayout:
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	}
+
+	return t.Format(layout), nil
+}
+
+// EnvMarshaler is the counterpart of EnvUnmarshaler, implemented by types that can format themselves
+// into the value of an environment variable.
+type EnvMarshaler interface {
+	MarshalEnvValue() (string, error)
+}
+
+var marshalerTypes = []reflect.Type{
+	reflect.TypeOf((*EnvMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+}
+
+// isMarshaler reports whether typ, or a pointer to typ, implements one of the supported marshaler interfaces.
+func isMarshaler(typ reflect.Type) bool {
+	for _, marshalerType := range marshalerTypes {
+		if typ.Implements(marshalerType) || reflect.PointerTo(typ).Implements(marshalerType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// marshalValue formats a value through the marshaler interface it implements.
+// Values are copied into a new pointer, so both value and pointer receivers are supported.
+func marshalValue(value reflect.Value) (string, error) {
+	target := value
+	if value.Kind() != reflect.Ptr {
+		target = reflect.New(value.Type())
+		target.Elem().Set(value)
+	}
+
+	switch marshaler := target.Interface().(type) {
+	case EnvMarshaler:
+		return marshaler.MarshalEnvValue()
+	case encoding.TextMarshaler:
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	case encoding.BinaryMarshaler:
+		data, err := marshaler.MarshalBinary()
+		return string(data), err
+	case json.Marshaler:
+		data, err := marshaler.MarshalJSON()
+		if err != nil {
+			return "", err
+		}
+		// JSON strings are unquoted, mirroring how values are decoded as JSON strings first
+		var s string
+		if json.Unmarshal(data, &s) == nil {
+			return s, nil
+		}
+		return string(data), nil
+	default:
+		return "", NoFormatterFoundError{value.Type().String()}
+	}
+}
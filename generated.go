@@ -0,0 +1,147 @@
+package goenv
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// StructUnmarshaler is implemented by structs which decode themselves from a Source without reflection,
+// usually through an UnmarshalEnv method generated by the goenvgen command:
+//
+//	//go:generate go run github.com/ilhamtubagus/goenv/cmd/goenvgen -type Config
+//
+// Unmarshal calls UnmarshalEnv with the process environment instead of decoding the struct by reflection.
+type StructUnmarshaler interface {
+	UnmarshalEnv(src Source) error
+}
+
+// The functions below are called by the code generated by goenvgen, so that it behaves exactly like Unmarshal.
+
+// LookupValue returns the value of a variable as Unmarshal reads it: the value set in src, or else the
+// default value of the field.
+//
+// Returns:
+//   - string: The value of the variable.
+//   - bool: Whether a value has been found. The field is left untouched when it has not.
+//   - error: A MissingRequiredError if the field is required and no value has been found.
+func LookupValue(src Source, path, envName, defaultValue string, required bool) (string, bool, error) {
+	if value, ok := src.Lookup(envName); ok {
+		return value, true, nil
+	}
+	if defaultValue != "" {
+		return defaultValue, true, nil
+	}
+	if required {
+		return "", false, MissingRequiredError{Field: path, Var: envName}
+	}
+
+	return "", false, nil
+}
+
+// NewParseError returns the ParseError reported by Unmarshal when value cannot be parsed into a field.
+// The zero argument is a value of the type the value was converted into. The envName and value arguments
+// are empty for the errors returned by the Validate method of a struct.
+func NewParseError(err error, path, envName, value string, zero interface{}) error {
+	return ParseError{
+		Field: path,
+		Var:   envName,
+		Value: value,
+		Type:  reflect.TypeOf(zero),
+		Err:   err,
+	}
+}
+
+// UnmarshalField decodes a single field by reflection, exactly as Unmarshal does.
+// It is used by the generated code for the fields it does not decode itself, like maps or times. The tag of a field
+// is only read on its first decoding, as its plan is cached.
+//
+// Parameters:
+//   - src: The source of the variables.
+//   - field: A pointer to the field.
+//   - path: The dotted path of the field from the decoded struct, e.g. Database.Port.
+//   - tag: The tag of the field.
+//   - prefix: The prefix of the variables of the struct holding the field.
+func UnmarshalField(src Source, field interface{}, path, tag, prefix string) error {
+	options := defaultOptions()
+	options.Source = src
+	options.Prefix = prefix
+
+	value := reflect.ValueOf(field).Elem()
+	fieldType := reflect.StructField{
+		Name: path[strings.LastIndex(path, ".")+1:],
+		Type: value.Type(),
+		Tag:  reflect.StructTag(tag),
+	}
+	plan := generatedFieldPlan(fieldType, path, options)
+	if plan == nil {
+		return nil
+	}
+
+	return parseField(value, plan, options)
+}
+
+// CheckConditions checks the conditional requirements of the fields of a decoded struct, exactly as Unmarshal
+// does. It is used by the generated code for the structs holding fields tagged requiredIf, requiredWith,
+// requiredWithout or excludedWith.
+//
+// Parameters:
+//   - src: The source of the variables.
+//   - target: A pointer to the struct.
+//   - path: The dotted path of the struct from the decoded struct, empty for the decoded struct itself.
+//   - prefix: The prefix of the variables of the struct.
+func CheckConditions(src Source, target interface{}, path, prefix string) error {
+	options := defaultOptions()
+	options.Source = src
+	options.Prefix = prefix
+
+	value := reflect.ValueOf(target).Elem()
+	if errs := checkConditions(value, generatedStructPlan(value.Type(), path, options), options); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// generatedKey identifies a plan compiled for the generated code, which depends on the path and the prefix of
+// the struct or field besides its type, and on the tag of fields.
+type generatedKey struct {
+	typ               reflect.Type
+	path, prefix, tag string
+}
+
+// generatedFieldPlans caches the plans of the fields decoded by UnmarshalField, nil for fields not decoded at all.
+var generatedFieldPlans sync.Map
+
+// generatedFieldPlan returns the plan of a field decoded with the default options by the generated code,
+// compiling it on first use, or nil when the field is not decoded at all.
+func generatedFieldPlan(fieldType reflect.StructField, path string, options Options) *fieldPlan {
+	key := generatedKey{typ: fieldType.Type, path: path, prefix: options.Prefix, tag: string(fieldType.Tag)}
+	if plan, ok := generatedFieldPlans.Load(key); ok {
+		return plan.(*fieldPlan)
+	}
+
+	var plan *fieldPlan
+	if field, ok := compileField(fieldType, path, options, make(map[reflect.Type]*structPlan)); ok {
+		plan = &field
+	}
+	cached, _ := generatedFieldPlans.LoadOrStore(key, plan)
+
+	return cached.(*fieldPlan)
+}
+
+// generatedStructPlans caches the plans of the structs checked by the generated code, as Decoder.plan does,
+// so the tags of a struct are only read on its first decoding.
+var generatedStructPlans sync.Map
+
+// generatedStructPlan returns the plan of a struct decoded with the default options by the generated code,
+// compiling it on first use.
+func generatedStructPlan(typ reflect.Type, path string, options Options) *structPlan {
+	key := generatedKey{typ: typ, path: path, prefix: options.Prefix}
+	if plan, ok := generatedStructPlans.Load(key); ok {
+		return plan.(*structPlan)
+	}
+	plan, _ := generatedStructPlans.LoadOrStore(key, compileStruct(typ, path, options))
+
+	return plan.(*structPlan)
+}
@@ -5,10 +5,13 @@ go 1.23.4
 require (
 	github.com/ilhamtubagus/condutil v0.1.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/tools v0.31.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
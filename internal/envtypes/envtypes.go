@@ -0,0 +1,136 @@
+// Package envtypes describes, in terms of go/types, how goenv decodes the fields of a struct with its
+// default options. It is shared by the envlint analyzer and the goenvgen generator.
+package envtypes
+
+import (
+	"go/types"
+)
+
+const (
+	// tag names and separator of the default options of goenv
+	TagName          = "env"
+	DefaultTagName   = "defaultEnv"
+	SeparatorTagName = "envSeparator"
+	PrefixTagName    = "envPrefix"
+	ValidateTagName  = "envValidate"
+	DefaultSeparator = ","
+	IgnoredTag       = "-"
+	RequiredOption   = "required"
+	FileOption       = "file"
+	SensitiveOption  = "sensitive"
+)
+
+// ConditionTagNames are the tag names of the conditional requirements of fields.
+var ConditionTagNames = []string{"requiredIf", "requiredWith", "requiredWithout", "excludedWith"}
+
+// parsedTypes lists the types with a built-in parser in goenv.
+var parsedTypes = map[string]bool{
+	"time.Duration":      true,
+	"time.Time":          true,
+	"*time.Location":     true,
+	"net/url.URL":        true,
+	"*net/url.URL":       true,
+	"net.IP":             true,
+	"net.IPNet":          true,
+	"*net.IPNet":         true,
+	"net.HardwareAddr":   true,
+	"net/netip.Addr":     true,
+	"net/netip.Prefix":   true,
+	"net/netip.AddrPort": true,
+	"net/mail.Address":   true,
+	"*net/mail.Address":  true,
+}
+
+// unmarshalerMethods lists the methods of the unmarshaler interfaces supported by goenv, in order of precedence,
+// along with the type of their argument.
+var unmarshalerMethods = []struct {
+	name    string
+	argType string
+}{
+	{"UnmarshalEnvValue", "string"},
+	{"UnmarshalText", "[]byte"},
+	{"UnmarshalBinary", "[]byte"},
+	{"UnmarshalJSON", "[]byte"},
+}
+
+// HasBuiltinParser reports whether typ has a built-in parser in goenv.
+func HasBuiltinParser(typ types.Type) bool {
+	return parsedTypes[types.TypeString(typ, nil)] || IsSecret(typ)
+}
+
+// IsSecret reports whether typ is an instance of goenv.Secret, whose values are parsed with the parser of their type.
+func IsSecret(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Origin().Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == "github.com/ilhamtubagus/goenv" && obj.Name() == "Secret"
+}
+
+// HasParser reports whether values of typ are parsed as a whole by goenv, through a built-in parser of
+// the type or an unmarshaler interface, rather than by their kind. Pointers to types with a built-in parser,
+// such as *time.Time, are not: goenv allocates them and parses their element.
+func HasParser(typ types.Type) bool {
+	if HasBuiltinParser(typ) {
+		return true
+	}
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok && HasBuiltinParser(ptr.Elem()) {
+		return false
+	}
+
+	return UnmarshalerMethod(typ) != ""
+}
+
+// UnmarshalerMethod returns the name of the method through which goenv unmarshals values of typ, or an empty
+// string when neither typ nor a pointer to typ implements one of the supported unmarshaler interfaces.
+func UnmarshalerMethod(typ types.Type) string {
+	for _, method := range unmarshalerMethods {
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, method.name)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		if sig.Params().Len() == 1 && sig.Results().Len() == 1 &&
+			types.TypeString(sig.Params().At(0).Type(), nil) == method.argType &&
+			types.TypeString(sig.Results().At(0).Type(), nil) == "error" {
+			return method.name
+		}
+	}
+
+	return ""
+}
+
+// HasValidateMethod reports whether typ or a pointer to typ has a Validate() error method, which goenv calls
+// once the fields of a struct are decoded.
+func HasValidateMethod(typ types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, "Validate")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+		types.TypeString(sig.Results().At(0).Type(), nil) == "error"
+}
+
+// IsParsedByKind reports whether goenv parses values of typ by their kind, i.e. typ is a boolean, a string,
+// an integer or a float.
+func IsParsedByKind(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+
+	return ok && basic.Kind() != types.Uintptr &&
+		basic.Info()&(types.IsBoolean|types.IsString|types.IsInteger|types.IsFloat) != 0
+}
+
+// JoinPath appends the name of a field to the dotted path of its struct.
+func JoinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,92 @@
+// Package gentest holds structs whose UnmarshalEnv methods are generated by goenvgen, to test the generated
+// code against the reflective decoding of goenv.
+package gentest
+
+import (
+	"errors"
+	"github.com/ilhamtubagus/goenv"
+	"strings"
+	"time"
+)
+
+//go:generate go run ../../cmd/goenvgen -type Config
+
+type Level int
+
+func (l *Level) UnmarshalEnvValue(value string) error {
+	switch value {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+
+	return nil
+}
+
+type Upper string
+
+func (u *Upper) UnmarshalText(text []byte) error {
+	*u = Upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+type Port uint16
+
+type Database struct {
+	Host     string `env:"HOST,required"`
+	Port     Port   `env:"PORT" defaultEnv:"5432"`
+	User     string `env:"USER" requiredWith:"Password"`
+	Password string `env:"PASSWORD" excludedWith:"TOKEN"`
+}
+
+func (d Database) Validate() error {
+	if d.Port == 0 {
+		return errors.New("port must not be zero")
+	}
+
+	return nil
+}
+
+type Common struct {
+	Version string `env:"VERSION"`
+}
+
+type Config struct {
+	Common
+
+	Name     string               `env:"NAME,required"`
+	Workers  int                  `env:"WORKERS" defaultEnv:"4"`
+	Small    int8                 `env:"SMALL"`
+	Big      uint64               `env:"BIG"`
+	Ratio    float32              `env:"RATIO"`
+	Debug    *bool                `env:"DEBUG"`
+	Count    *int                 `env:"COUNT"`
+	Ports    []int                `env:"PORTS" envSeparator:";"`
+	Tags     []string             `env:"TAGS" defaultEnv:"a,b"`
+	Level    Level                `env:"LEVEL" defaultEnv:"info"`
+	Levels   []Level              `env:"LEVELS"`
+	Upper    Upper                `env:"UPPER"`
+	Mode     string               `env:"MODE" envValidate:"oneof=dev prod"`
+	Key      string               `env:"KEY" requiredIf:"Mode=prod"`
+	Secret   string               `env:"SECRET,file"`
+	Password goenv.Secret[string] `env:"PASSWORD"`
+	PIN      int                  `env:"PIN,sensitive"`
+	Timeout  time.Duration        `env:"TIMEOUT" defaultEnv:"5s"`
+	Started  time.Time            `env:"STARTED"`
+	Labels   map[string]string    `env:"LABELS"`
+	Database Database             `envPrefix:"DB_"`
+	Replica  *Database            `envPrefix:"REPLICA_"`
+	Ignored  string               `env:"-"`
+	Untagged string
+}
+
+func (c *Config) Validate() error {
+	if c.Name == c.Database.Host {
+		return errors.New("name and database host must differ")
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// Code generated by goenvgen; DO NOT EDIT.
+
+package gentest
+
+import (
+	"github.com/ilhamtubagus/goenv"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalEnv populates the fields of Config from the variables of src, like goenv.Unmarshal does
+// by reflection.
+func (c *Config) UnmarshalEnv(src goenv.Source) error {
+	if value, ok, err := goenv.LookupValue(src, "Common.Version", "VERSION", "", false); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Common.Version = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Name", "NAME", "", true); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Name = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Workers", "WORKERS", "4", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return goenv.NewParseError(err, "Workers", "WORKERS", value, *new(int))
+		}
+		parsedValue := int(parsed)
+		c.Workers = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Small", "SMALL", "", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			return goenv.NewParseError(err, "Small", "SMALL", value, *new(int8))
+		}
+		parsedValue := int8(parsed)
+		c.Small = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Big", "BIG", "", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return goenv.NewParseError(err, "Big", "BIG", value, *new(uint64))
+		}
+		parsedValue := parsed
+		c.Big = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Ratio", "RATIO", "", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return goenv.NewParseError(err, "Ratio", "RATIO", value, *new(float32))
+		}
+		parsedValue := float32(parsed)
+		c.Ratio = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Debug", "DEBUG", "", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return goenv.NewParseError(err, "Debug", "DEBUG", value, *new(bool))
+		}
+		parsedValue := parsed
+		c.Debug = &parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Count", "COUNT", "", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return goenv.NewParseError(err, "Count", "COUNT", value, *new(int))
+		}
+		parsedValue := int(parsed)
+		c.Count = &parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Ports", "PORTS", "", false); err != nil {
+		return err
+	} else if ok {
+		parts := strings.Split(value, ";")
+		values := make([]int, 0, len(parts))
+		for index, part := range parts {
+			parsed, err := strconv.ParseInt(part, 10, 32)
+			if err != nil {
+				return goenv.NewParseError(err, "Ports["+strconv.Itoa(index)+"]", "PORTS", part, *new(int))
+			}
+			parsedValue := int(parsed)
+			values = append(values, parsedValue)
+		}
+		c.Ports = values
+	}
+	if value, ok, err := goenv.LookupValue(src, "Tags", "TAGS", "a,b", false); err != nil {
+		return err
+	} else if ok {
+		parts := strings.Split(value, ",")
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			parsedValue := part
+			values = append(values, parsedValue)
+		}
+		c.Tags = values
+	}
+	if value, ok, err := goenv.LookupValue(src, "Level", "LEVEL", "info", false); err != nil {
+		return err
+	} else if ok {
+		var parsedValue Level
+		if err := parsedValue.UnmarshalEnvValue(value); err != nil {
+			return goenv.NewParseError(err, "Level", "LEVEL", value, *new(Level))
+		}
+		c.Level = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Levels", "LEVELS", "", false); err != nil {
+		return err
+	} else if ok {
+		parts := strings.Split(value, ",")
+		values := make([]Level, 0, len(parts))
+		for index, part := range parts {
+			var parsedValue Level
+			if err := parsedValue.UnmarshalEnvValue(part); err != nil {
+				return goenv.NewParseError(err, "Levels["+strconv.Itoa(index)+"]", "LEVELS", part, *new(Level))
+			}
+			values = append(values, parsedValue)
+		}
+		c.Levels = values
+	}
+	if value, ok, err := goenv.LookupValue(src, "Upper", "UPPER", "", false); err != nil {
+		return err
+	} else if ok {
+		var parsedValue Upper
+		if err := parsedValue.UnmarshalText([]byte(value)); err != nil {
+			return goenv.NewParseError(err, "Upper", "UPPER", value, *new(Upper))
+		}
+		c.Upper = parsedValue
+	}
+	if err := goenv.UnmarshalField(src, &c.Mode, "Mode", `env:"MODE" envValidate:"oneof=dev prod"`, ""); err != nil {
+		return err
+	}
+	if value, ok, err := goenv.LookupValue(src, "Key", "KEY", "", false); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Key = parsedValue
+	}
+	if err := goenv.UnmarshalField(src, &c.Secret, "Secret", `env:"SECRET,file"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.UnmarshalField(src, &c.Password, "Password", `env:"PASSWORD"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.UnmarshalField(src, &c.PIN, "PIN", `env:"PIN,sensitive"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.UnmarshalField(src, &c.Timeout, "Timeout", `env:"TIMEOUT" defaultEnv:"5s"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.UnmarshalField(src, &c.Started, "Started", `env:"STARTED"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.UnmarshalField(src, &c.Labels, "Labels", `env:"LABELS"`, ""); err != nil {
+		return err
+	}
+	if value, ok, err := goenv.LookupValue(src, "Database.Host", "DB_HOST", "", true); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Database.Host = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Database.Port", "DB_PORT", "5432", false); err != nil {
+		return err
+	} else if ok {
+		parsed, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return goenv.NewParseError(err, "Database.Port", "DB_PORT", value, *new(Port))
+		}
+		parsedValue := Port(parsed)
+		c.Database.Port = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Database.User", "DB_USER", "", false); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Database.User = parsedValue
+	}
+	if value, ok, err := goenv.LookupValue(src, "Database.Password", "DB_PASSWORD", "", false); err != nil {
+		return err
+	} else if ok {
+		parsedValue := value
+		c.Database.Password = parsedValue
+	}
+	if err := goenv.CheckConditions(src, &c.Database, "Database", "DB_"); err != nil {
+		return err
+	}
+	if err := c.Database.Validate(); err != nil {
+		return goenv.NewParseError(err, "Database", "", "", *new(Database))
+	}
+	if err := goenv.UnmarshalField(src, &c.Replica, "Replica", `envPrefix:"REPLICA_"`, ""); err != nil {
+		return err
+	}
+	if err := goenv.CheckConditions(src, c, "", ""); err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return goenv.NewParseError(err, "", "", "", *new(Config))
+	}
+	return nil
+}
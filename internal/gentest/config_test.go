@@ -0,0 +1,75 @@
+package gentest
+
+import (
+	"github.com/ilhamtubagus/goenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestUnmarshalEnv checks that the generated code decodes exactly like the reflective path of goenv.
+func TestUnmarshalEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		source goenv.MapSource
+	}{
+		{"Defaults", goenv.MapSource{"NAME": "app", "DB_HOST": "db"}},
+		{"All fields", goenv.MapSource{
+			"VERSION":        "1.2",
+			"NAME":           "app",
+			"WORKERS":        "8",
+			"SMALL":          "-3",
+			"BIG":            "18446744073709551615",
+			"RATIO":          "0.5",
+			"DEBUG":          "true",
+			"COUNT":          "0",
+			"PORTS":          "80;443",
+			"TAGS":           "x",
+			"LEVEL":          "debug",
+			"LEVELS":         "info,debug",
+			"UPPER":          "loud",
+			"MODE":           "prod",
+			"KEY":            "secret",
+			"PASSWORD":       "s3cret",
+			"PIN":            "1234",
+			"TIMEOUT":        "10",
+			"STARTED":        "2024-01-02T03:04:05Z",
+			"LABELS_TEAM":    "core",
+			"DB_HOST":        "db",
+			"DB_PORT":        "6543",
+			"REPLICA_HOST":   "replica",
+			"UNTAGGED":       "ignored",
+			"IGNORED":        "ignored",
+			"LABELS_OWNER_X": "me",
+		}},
+		{"Missing required", goenv.MapSource{"DB_HOST": "db"}},
+		{"Missing nested required", goenv.MapSource{"NAME": "app"}},
+		{"Invalid int", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "WORKERS": "many"}},
+		{"Out of range", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "SMALL": "300"}},
+		{"Invalid bool", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "DEBUG": "maybe"}},
+		{"Invalid slice element", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "PORTS": "80;http"}},
+		{"Invalid unmarshaler", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "LEVELS": "info,loud"}},
+		{"Invalid nested", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "DB_PORT": "70000"}},
+		{"Invalid validated value", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "MODE": "test"}},
+		{"Invalid nested struct", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "DB_PORT": "0"}},
+		{"Invalid pointer to struct value", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "REPLICA_HOST": "r", "REPLICA_PORT": "0"}},
+		{"Invalid struct", goenv.MapSource{"NAME": "db", "DB_HOST": "db"}},
+		{"Missing conditionally required", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "MODE": "prod"}},
+		{"Missing nested conditionally required", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "DB_PASSWORD": "p"}},
+		{"Excluded variable", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "DB_USER": "u", "DB_PASSWORD": "p", "TOKEN": "t"}},
+		{"Missing secret file", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "SECRET_FILE": "testdata/missing"}},
+		{"Secret set twice", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "SECRET": "s", "SECRET_FILE": "testdata/missing"}},
+		{"Invalid sensitive value", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "PIN": "12a4"}},
+		{"Invalid fallback", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "TIMEOUT": "soon"}},
+		{"Invalid pointer to struct", goenv.MapSource{"NAME": "app", "DB_HOST": "db", "REPLICA_PORT": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected, actual Config
+			expectedErr := goenv.NewDecoder(goenv.WithSource(tt.source)).Decode(&expected)
+			actualErr := actual.UnmarshalEnv(tt.source)
+
+			assert.Equal(t, expectedErr, actualErr)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
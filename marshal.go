@@ -0,0 +1,243 @@
+package goenv
+
+import (
+	"fmt"
+	"github.com/ilhamtubagus/condutil"
+	"reflect"
+	"strings"
+)
+
+// Encoder is the counterpart of Decoder: it serialises structs back into environment variables
+// using the same tags, separators, prefixes and naming strategy. An Encoder is safe for concurrent use.
+type Encoder struct {
+	options Options
+}
+
+// NewEncoder creates an Encoder configured with the default options modified by opts.
+func NewEncoder(opts ...Option) *Encoder {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Encoder{options: options.withDefaults()}
+}
+
+// Marshal serialises a struct into a map of environment variable names to values, the inverse of Unmarshal.
+//
+// Parameters:
+//   - v: A struct, or a pointer to a struct, whose fields are tagged like the ones given to Unmarshal.
+//
+// Returns:
+//   - map[string]string: The environment variables, which can be written with WriteDotenv or a similar writer.
+//     Nil pointers, nil slices and nil maps are left out, so the result round-trips through Unmarshal.
+//     Nil elements of slices cannot be left out without shifting the others, and are reported with NilElementError.
+//     Map keys are converted to UPPER_SNAKE_CASE, and are read back in lowerCamelCase: only lowerCamelCase keys,
+//     like the ones Unmarshal produces, round-trip unchanged. HTTPServer is read back as httpServer, a_b as aB.
+//   - error: An error if a field cannot be formatted.
+func Marshal(v interface{}) (map[string]string, error) {
+	return NewEncoder().Encode(v)
+}
+
+// MarshalWithOptions behaves like Marshal but uses the given options.
+// Fields left empty in options fall back to their default values.
+func MarshalWithOptions(v interface{}, options Options) (map[string]string, error) {
+	encoder := &Encoder{options: options.withDefaults()}
+
+	return encoder.Encode(v)
+}
+
+// Encode serialises a struct, or a pointer to a struct, into a map of environment variable names to values.
+// See Marshal for details.
+func (e *Encoder) Encode(v interface{}) (map[string]string, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, NotStructPtrError{
+			actualType: value.Kind().String(),
+		}
+	}
+
+	vars := make(map[string]string)
+	if err := encodeStruct(value, "", vars, e.options); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+func encodeStruct(value reflect.Value, path string, vars map[string]string, options Options) error {
+	typeRef := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		if err := encodeField(value.Field(i), typeRef.Field(i), joinPath(path, typeRef.Field(i).Name), vars, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeField(field reflect.Value, fieldType reflect.StructField, path string, vars map[string]string, options Options) error {
+	// unexported fields cannot be read, embedded structs are still walked for their exported fields
+	if fieldType.Tag.Get(options.TagName) == ignoredTag || (!fieldType.IsExported() && !fieldType.Anonymous) {
+		return nil
+	}
+
+	if field.Kind() == reflect.Struct && !hasFormatter(field.Type(), options) {
+		return encodeStruct(field, path, vars, nestedOptions(fieldType, options))
+	}
+	if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct && !hasFormatter(field.Type(), options) &&
+		!hasFormatter(field.Type().Elem(), options) {
+		if field.IsNil() {
+			return nil
+		}
+		return encodeStruct(field.Elem(), path, vars, nestedOptions(fieldType, options))
+	}
+
+	envName, _ := parseTag(fieldType.Tag.Get(options.TagName))
+	if condutil.IsZeroValue(envName) && options.Naming != nil && fieldType.IsExported() {
+		envName = options.Naming(fieldType.Name)
+	}
+	if condutil.IsZeroValue(envName) {
+		return nil
+	}
+	envName = options.Prefix + envName
+
+	// nil values are left out, so they are not set to a zero value when decoded back
+	for field.Kind() == reflect.Ptr && !hasFormatter(field.Type(), options) {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if (field.Kind() == reflect.Slice || field.Kind() == reflect.Map) && field.IsNil() {
+		return nil
+	}
+
+	if !hasFormatter(field.Type(), options) {
+		switch field.Kind() {
+		case reflect.Slice:
+			return encodeSlice(field, fieldType, path, envName, vars, options)
+		case reflect.Map:
+			return encodeMap(field, fieldType, path, envName, vars, options)
+		}
+	}
+
+	value, err := formatValue(field, fieldType.Tag, options)
+	if err != nil {
+		return newFormatError(err, path, envName)
+	}
+	vars[envName] = value
+
+	return nil
+}
+
+func encodeSlice(field reflect.Value, fieldType reflect.StructField, path, envName string, vars map[string]string, options Options) error {
+	separator := fieldType.Tag.Get(options.SeparatorTagName)
+	if condutil.IsZeroValue(separator) {
+		separator = options.Separator
+	}
+
+	values := make([]string, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		elem := field.Index(i)
+		// Unmarshal never decodes elements into nil pointers, so these could not be read back
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return newFormatError(NilElementError, fmt.Sprintf("%s[%d]", path, i), envName)
+		}
+		if elem.Kind() == reflect.Ptr && !hasFormatter(elem.Type(), options) {
+			elem = elem.Elem()
+		}
+		value, err := formatValue(elem, fieldType.Tag, options)
+		if err != nil {
+			return newFormatError(err, fmt.Sprintf("%s[%d]", path, i), envName)
+		}
+		values = append(values, value)
+	}
+	vars[envName] = strings.Join(values, separator)
+
+	return nil
+}
+
+// encodeMap stores each entry of a map in its own variable named after the prefix and the key,
+// e.g. the key fieldOne of a map tagged `env:"OPTIONS"` is stored in OPTIONS_FIELD_ONE. Keys which are not
+// lowerCamelCase are read back changed by handleMap, see Marshal.
+func encodeMap(field reflect.Value, fieldType reflect.StructField, path, envName string, vars map[string]string, options Options) error {
+	if field.Type().Key().Kind() != reflect.String {
+		return InvalidMapKeyError
+	}
+
+	iter := field.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		value, err := formatValue(iter.Value(), fieldType.Tag, options)
+		if err != nil {
+			return newFormatError(err, path+"["+key+"]", envName)
+		}
+		vars[envName+"_"+UpperSnakeCase(key)] = value
+	}
+
+	return nil
+}
+
+// hasFormatter reports whether values of typ are formatted as a whole, through a custom formatter, the
+// formatter of the value of a Secret, a built-in formatter of the type or a marshaler interface, rather than
+// by their kind.
+func hasFormatter(typ reflect.Type, options Options) bool {
+	if _, ok := options.FormatMap[typ]; ok {
+		return true
+	}
+	// pointers to secrets are not formatted through the redacting marshalers of Secret, but like other pointers
+	if holdsSecret(typ) {
+		return isSecret(typ)
+	}
+	// pointers to types with a custom or built-in formatter are formatted through it, see hasParser
+	if typ.Kind() == reflect.Ptr {
+		if _, ok := options.FormatMap[typ.Elem()]; ok {
+			return false
+		}
+		if _, ok := defaultTypeFormatter[typ.Elem()]; ok {
+			return false
+		}
+	}
+	if _, ok := defaultTypeFormatter[typ]; ok {
+		return true
+	}
+
+	return isMarshaler(typ)
+}
+
+// formatValue converts a value into a string, using in order the custom formatter registered for the type,
+// the formatter of the value of a Secret, the built-in formatter of the type, the marshaler interface implemented
+// by the type or the default formatter of its kind. The tag is the tag of the field being formatted.
+func formatValue(value reflect.Value, tag reflect.StructTag, options Options) (string, error) {
+	if formatFunc, ok := options.FormatMap[value.Type()]; ok {
+		return formatFunc(value.Interface())
+	}
+	if isSecret(value.Type()) {
+		return formatSecret(value, tag, options)
+	}
+	if typeFormatFunc, ok := defaultTypeFormatter[value.Type()]; ok {
+		return typeFormatFunc(value.Interface(), tag, options)
+	}
+	if isMarshaler(value.Type()) {
+		return marshalValue(value)
+	}
+	if formatFunc, ok := defaultFormatter[value.Kind()]; ok {
+		return formatFunc(value.Interface())
+	}
+
+	return "", NoFormatterFoundError{value.Type().String()}
+}
+
+// newFormatError wraps an error returned while formatting a value in a FieldError.
+// Errors telling that the type cannot be formatted at all are returned unchanged.
+func newFormatError(err error, path, envName string) error {
+	if _, ok := err.(NoFormatterFoundError); ok {
+		return err
+	}
+
+	return FieldError{Field: path, Var: envName, Err: err}
+}
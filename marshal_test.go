@@ -0,0 +1,285 @@
+package goenv
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type marshalLevel int
+
+func (l marshalLevel) MarshalEnvValue() (string, error) {
+	return [...]string{"debug", "info"}[l], nil
+}
+
+func (l marshalLevel) String() string {
+	value, _ := l.MarshalEnvValue()
+	return value
+}
+
+func (l *marshalLevel) UnmarshalEnvValue(value string) error {
+	switch value {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type marshalDB struct {
+	Host     string            `env:"HOST"`
+	Port     int               `env:"PORT"`
+	Params   map[string]string `env:"PARAMS"`
+	Replicas []string          `env:"REPLICAS" envSeparator:";"`
+}
+
+type marshalConfig struct {
+	Name      string                   `env:"NAME"`
+	Debug     bool                     `env:"DEBUG"`
+	Ratio     float32                  `env:"RATIO"`
+	Ports     []uint16                 `env:"PORTS"`
+	Weights   map[string]time.Duration `env:"WEIGHTS"`
+	Level     marshalLevel             `env:"LEVEL"`
+	Timeout   time.Duration            `env:"TIMEOUT"`
+	StartAt   time.Time                `env:"START_AT"`
+	CreatedAt time.Time                `env:"CREATED_AT" envLayout:"unix"`
+	Endpoint  *url.URL                 `env:"ENDPOINT"`
+	IPs       []net.IP                 `env:"IPS"`
+	Listen    netip.AddrPort           `env:"LISTEN"`
+	Motd      string                   `env:"MOTD"`
+	Optional  *int                     `env:"OPTIONAL"`
+	Primary   marshalDB                `envPrefix:"PRIMARY_"`
+	Replica   *marshalDB               `envPrefix:"REPLICA_"`
+	Ignored   string                   `env:"-"`
+	Untagged  string
+}
+
+func newMarshalConfig() marshalConfig {
+	endpoint, _ := url.Parse("https://example.com/api?x=1")
+	return marshalConfig{
+		Name:      "goenv",
+		Debug:     true,
+		Ratio:     0.25,
+		Ports:     []uint16{80, 443},
+		Weights:   map[string]time.Duration{"readTimeout": 1500 * time.Millisecond},
+		Level:     1,
+		Timeout:   90 * time.Second,
+		StartAt:   time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC),
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		Endpoint:  endpoint,
+		IPs:       []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")},
+		Listen:    netip.MustParseAddrPort("0.0.0.0:8080"),
+		Motd:      "Hello \"world\"\n\tit's $HOME #1",
+		Primary: marshalDB{
+			Host:     "primary",
+			Port:     5432,
+			Params:   map[string]string{"sslMode": "require"},
+			Replicas: []string{"a", "b"},
+		},
+		Ignored:  "ignored",
+		Untagged: "untagged",
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	vars, err := Marshal(newMarshalConfig())
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"NAME":                    "goenv",
+		"DEBUG":                   "true",
+		"RATIO":                   "0.25",
+		"PORTS":                   "80,443",
+		"WEIGHTS_READ_TIMEOUT":    "1.5s",
+		"LEVEL":                   "info",
+		"TIMEOUT":                 "1m30s",
+		"START_AT":                "2024-05-01T10:00:00.0000005Z",
+		"CREATED_AT":              "1700000000",
+		"ENDPOINT":                "https://example.com/api?x=1",
+		"IPS":                     "10.0.0.1,::1",
+		"LISTEN":                  "0.0.0.0:8080",
+		"MOTD":                    "Hello \"world\"\n\tit's $HOME #1",
+		"PRIMARY_HOST":            "primary",
+		"PRIMARY_PORT":            "5432",
+		"PRIMARY_PARAMS_SSL_MODE": "require",
+		"PRIMARY_REPLICAS":        "a;b",
+	}, vars)
+
+	t.Run("Round trip", func(t *testing.T) {
+		actualStruct := &marshalConfig{}
+		err := NewDecoder(WithSource(MapSource(vars))).Decode(actualStruct)
+
+		expected := newMarshalConfig()
+		expected.Ignored, expected.Untagged = "", ""
+		assert.Nil(t, err)
+		assert.Equal(t, expected, *actualStruct)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		type Config struct {
+			MaxConns int
+			Hosts    []string
+			Level    marshalLevel
+		}
+		vars, err := NewEncoder(
+			WithNaming(UpperSnakeCase),
+			WithPrefix("APP_"),
+			WithSeparator("|"),
+			WithFormatter(reflect.TypeOf(marshalLevel(0)), func(v interface{}) (string, error) {
+				return strings.ToUpper(v.(marshalLevel).String()), nil
+			}),
+		).Encode(&Config{MaxConns: 5, Hosts: []string{"a", "b"}, Level: 0})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"APP_MAX_CONNS": "5", "APP_HOSTS": "a|b", "APP_LEVEL": "DEBUG"}, vars)
+	})
+
+	t.Run("JSON marshaler", func(t *testing.T) {
+		type Config struct {
+			ID jsonID `env:"ID"`
+		}
+
+		vars, err := Marshal(Config{ID: jsonID{"123"}})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"ID": "123"}, vars)
+
+		actualStruct := &Config{}
+		err = NewDecoder(WithSource(MapSource(vars))).Decode(actualStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, jsonID{"123"}, actualStruct.ID)
+	})
+
+	t.Run("Pointer to time", func(t *testing.T) {
+		type Config struct {
+			When  *time.Time   `env:"WHEN" envLayout:"DateOnly"`
+			Dates []*time.Time `env:"DATES" envLayout:"DateOnly"`
+		}
+		when := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		vars, err := Marshal(Config{When: &when, Dates: []*time.Time{&when}})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"WHEN": "2024-01-02", "DATES": "2024-01-02"}, vars)
+
+		actualStruct := &Config{}
+		err = NewDecoder(WithSource(MapSource(vars))).Decode(actualStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, when, *actualStruct.When)
+	})
+
+	t.Run("Map keys", func(t *testing.T) {
+		type Config struct {
+			Options map[string]string `env:"OPTIONS"`
+		}
+		config := Config{Options: map[string]string{"readTimeout": "1", "HTTPServer": "2", "a_b": "3", "x": "4"}}
+
+		vars, err := Marshal(config)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{
+			"OPTIONS_READ_TIMEOUT": "1",
+			"OPTIONS_HTTP_SERVER":  "2",
+			"OPTIONS_A_B":          "3",
+			"OPTIONS_X":            "4",
+		}, vars)
+
+		// only lowerCamelCase keys are read back unchanged
+		actualStruct := &Config{}
+		err = NewDecoder(WithSource(MapSource(vars))).Decode(actualStruct)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"readTimeout": "1", "httpServer": "2", "aB": "3", "x": "4"}, actualStruct.Options)
+	})
+
+	t.Run("Not a struct", func(t *testing.T) {
+		_, err := Marshal("value")
+
+		assert.IsType(t, NotStructPtrError{}, err)
+	})
+
+	t.Run("Nil slice element", func(t *testing.T) {
+		one := 1
+		_, err := Marshal(struct {
+			Ports []*int `env:"PORTS"`
+		}{Ports: []*int{&one, nil}})
+
+		assert.Equal(t, FieldError{Field: "Ports[1]", Var: "PORTS", Err: NilElementError}, err)
+		assert.ErrorIs(t, err, NilElementError)
+	})
+
+	t.Run("No formatter found", func(t *testing.T) {
+		_, err := Marshal(struct {
+			Fn func() `env:"FN"`
+		}{Fn: func() {}})
+
+		assert.Equal(t, NoFormatterFoundError{"func()"}, err)
+	})
+}
+
+func TestWriters(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":  "value",
+		"SPACED": "hello world",
+		"QUOTES": `it's "quoted" $HOME`,
+		"MULTI":  "first\nsecond",
+		"EMPTY":  "",
+	}
+
+	t.Run("Dotenv", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteDotenv(&buf, vars)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "EMPTY=\n"+
+			"MULTI=\"first\\nsecond\"\n"+
+			"PLAIN=value\n"+
+			"QUOTES=\"it's \\\"quoted\\\" \\$HOME\"\n"+
+			"SPACED=\"hello world\"\n", buf.String())
+
+		parsed, err := ReadDotenv(&buf)
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource(vars), parsed)
+	})
+
+	t.Run("Docker env file", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteDockerEnvFile(&buf, map[string]string{"A": "hello world", "B": `"raw"`})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "A=hello world\nB=\"raw\"\n", buf.String())
+
+		err = WriteDockerEnvFile(&buf, vars)
+		assert.ErrorIs(t, err, MultilineValueError)
+	})
+
+	t.Run("Systemd environment file", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteSystemdEnvFile(&buf, vars)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "EMPTY=\n"+
+			"MULTI=\"first\nsecond\"\n"+
+			"PLAIN=value\n"+
+			"QUOTES=\"it's \\\"quoted\\\" \\$HOME\"\n"+
+			"SPACED=\"hello world\"\n", buf.String())
+	})
+
+	t.Run("Export script", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteExportScript(&buf, vars)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "export EMPTY=\n"+
+			"export MULTI='first\nsecond'\n"+
+			"export PLAIN=value\n"+
+			"export QUOTES='it'\\''s \"quoted\" $HOME'\n"+
+			"export SPACED='hello world'\n", buf.String())
+	})
+}
@@ -0,0 +1,35 @@
+package goenv
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the name of an environment variable from the name of a struct field.
+type NamingStrategy func(fieldName string) string
+
+// UpperCase is the NamingStrategy converting field names into upper case without separating words,
+// e.g. MaxIdleConns becomes MAXIDLECONNS.
+func UpperCase(fieldName string) string {
+	return strings.ToUpper(fieldName)
+}
+
+// UpperSnakeCase is the NamingStrategy converting field names into UPPER_SNAKE_CASE, keeping acronyms together,
+// e.g. MaxIdleConns becomes MAX_IDLE_CONNS and HTTPServer becomes HTTP_SERVER.
+func UpperSnakeCase(fieldName string) string {
+	runes := []rune(fieldName)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// a new word starts after a lower case letter or a digit, or at the last capital of an acronym
+			endOfAcronym := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || endOfAcronym {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+
+	return sb.String()
+}
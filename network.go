@@ -0,0 +1,109 @@
+package goenv
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+)
+
+func parseURL(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidURLError, err)
+	}
+
+	return u, nil
+}
+
+func parseURLValue(value string, tag reflect.StructTag, options Options) (interface{}, error) {
+	u, err := parseURL(value, tag, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return *u.(*url.URL), nil
+}
+
+func parseIP(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, InvalidIPError
+	}
+
+	return ip, nil
+}
+
+func parseIPNet(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidCIDRError, err)
+	}
+
+	return ipNet, nil
+}
+
+func parseIPNetValue(value string, tag reflect.StructTag, options Options) (interface{}, error) {
+	ipNet, err := parseIPNet(value, tag, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return *ipNet.(*net.IPNet), nil
+}
+
+func parseHardwareAddr(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	mac, err := net.ParseMAC(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidMACError, err)
+	}
+
+	return mac, nil
+}
+
+func parseAddr(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidIPError, err)
+	}
+
+	return addr, nil
+}
+
+func parsePrefix(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidCIDRError, err)
+	}
+
+	return prefix, nil
+}
+
+func parseAddrPort(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	addrPort, err := netip.ParseAddrPort(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidAddrPortError, err)
+	}
+
+	return addrPort, nil
+}
+
+func parseMailAddress(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", InvalidMailAddressError, err)
+	}
+
+	return address, nil
+}
+
+func parseMailAddressValue(value string, tag reflect.StructTag, options Options) (interface{}, error) {
+	address, err := parseMailAddress(value, tag, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return *address.(*mail.Address), nil
+}
@@ -0,0 +1,92 @@
+package goenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestUnmarshal_Network(t *testing.T) {
+	type Config struct {
+		Endpoint    url.URL                   `env:"ENDPOINT"`
+		Callback    *url.URL                  `env:"CALLBACK"`
+		Mirrors     []*url.URL                `env:"MIRRORS"`
+		IP          net.IP                    `env:"IP"`
+		Network     *net.IPNet                `env:"NETWORK"`
+		Networks    []net.IPNet               `env:"NETWORKS"`
+		MAC         net.HardwareAddr          `env:"MAC"`
+		Addr        netip.Addr                `env:"ADDR"`
+		Prefix      netip.Prefix              `env:"PREFIX"`
+		Listen      netip.AddrPort            `env:"LISTEN"`
+		Upstreams   map[string]netip.AddrPort `env:"UPSTREAMS"`
+		Admin       mail.Address              `env:"ADMIN"`
+		Maintainers []*mail.Address           `env:"MAINTAINERS" envSeparator:";"`
+	}
+	source := MapSource{
+		"ENDPOINT":        "https://example.com/api",
+		"CALLBACK":        "http://localhost:8080/cb",
+		"MIRRORS":         "https://a.example.com,https://b.example.com",
+		"IP":              "192.168.1.1",
+		"NETWORK":         "10.0.0.0/8",
+		"NETWORKS":        "10.0.0.0/8,fd00::/8",
+		"MAC":             "00:00:5e:00:53:01",
+		"ADDR":            "::1",
+		"PREFIX":          "192.168.0.0/16",
+		"LISTEN":          "0.0.0.0:8080",
+		"UPSTREAMS_CACHE": "127.0.0.1:6379",
+		"ADMIN":           "Admin <admin@example.com>",
+		"MAINTAINERS":     "a@example.com;B <b@example.com>",
+	}
+
+	actualStruct := &Config{}
+	err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com", actualStruct.Endpoint.Host)
+	assert.Equal(t, "/cb", actualStruct.Callback.Path)
+	assert.Equal(t, "b.example.com", actualStruct.Mirrors[1].Host)
+	assert.Equal(t, net.ParseIP("192.168.1.1"), actualStruct.IP)
+	assert.Equal(t, "10.0.0.0/8", actualStruct.Network.String())
+	assert.Equal(t, "fd00::/8", actualStruct.Networks[1].String())
+	assert.Equal(t, "00:00:5e:00:53:01", actualStruct.MAC.String())
+	assert.Equal(t, netip.MustParseAddr("::1"), actualStruct.Addr)
+	assert.Equal(t, netip.MustParsePrefix("192.168.0.0/16"), actualStruct.Prefix)
+	assert.Equal(t, netip.MustParseAddrPort("0.0.0.0:8080"), actualStruct.Listen)
+	assert.Equal(t, map[string]netip.AddrPort{"cache": netip.MustParseAddrPort("127.0.0.1:6379")}, actualStruct.Upstreams)
+	assert.Equal(t, mail.Address{Name: "Admin", Address: "admin@example.com"}, actualStruct.Admin)
+	assert.Equal(t, []*mail.Address{{Address: "a@example.com"}, {Name: "B", Address: "b@example.com"}}, actualStruct.Maintainers)
+
+	t.Run("Invalid values", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			source   MapSource
+			field    string
+			expected error
+		}{
+			{"URL", MapSource{"ENDPOINT": "http://[::1"}, "Endpoint", InvalidURLError},
+			{"IP", MapSource{"IP": "999.1.1.1"}, "IP", InvalidIPError},
+			{"CIDR", MapSource{"NETWORK": "10.0.0.0"}, "Network", InvalidCIDRError},
+			{"CIDR slice element", MapSource{"NETWORKS": "10.0.0.0/8,nope"}, "Networks[1]", InvalidCIDRError},
+			{"MAC", MapSource{"MAC": "00:00"}, "MAC", InvalidMACError},
+			{"Addr", MapSource{"ADDR": "localhost"}, "Addr", InvalidIPError},
+			{"Prefix", MapSource{"PREFIX": "192.168.0.0/99"}, "Prefix", InvalidCIDRError},
+			{"AddrPort", MapSource{"LISTEN": "0.0.0.0"}, "Listen", InvalidAddrPortError},
+			{"AddrPort map value", MapSource{"UPSTREAMS_DB": "db"}, "Upstreams[db]", InvalidAddrPortError},
+			{"Mail address", MapSource{"ADMIN": "admin"}, "Admin", InvalidMailAddressError},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := NewDecoder(WithSource(tt.source)).Decode(&Config{})
+
+				var parseErr ParseError
+				assert.True(t, errors.As(err, &parseErr))
+				assert.Equal(t, tt.field, parseErr.Field)
+				assert.ErrorIs(t, err, tt.expected)
+			})
+		}
+	})
+}
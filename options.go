@@ -0,0 +1,356 @@
+package goenv
+
+import (
+	"reflect"
+	"time"
+)
+
+type Options struct {
+	// TagName is the tag name used to specify the environment variable name.
+	TagName string
+
+	// DefaultTagName is the default tag name to be used if no tag name is specified in the struct fields.
+	DefaultTagName string
+
+	// PrefixTagName is the tag name used to specify the prefix of the variables of a nested struct.
+	PrefixTagName string
+
+	// SeparatorTagName is the tag name used to specify the separator for splitting the environment variable value into multiple values.
+	SeparatorTagName string
+
+	// DescriptionTagName is the tag name used to document a field, see Describe.
+	DescriptionTagName string
+
+	// ExampleTagName is the tag name used to give an example value of a field, see Describe.
+	ExampleTagName string
+
+	// LayoutTagName is the tag name used to specify the layout of time.Time fields.
+	LayoutTagName string
+
+	// ValidateTagName is the tag name used to specify the rules the values of a field must satisfy.
+	ValidateTagName string
+
+	// RequiredIfTagName, RequiredWithTagName, RequiredWithoutTagName and ExcludedWithTagName are the tag names
+	// used to specify the conditional requirements of a field.
+	RequiredIfTagName      string
+	RequiredWithTagName    string
+	RequiredWithoutTagName string
+	ExcludedWithTagName    string
+
+	// Separator is the separator used to split the environment variable value into multiple values (used on slices or maps).
+	Separator string
+
+	// Prefix is prepended to the name of every variable. Nested structs append their own prefix to it.
+	Prefix string
+
+	// AutoPrefix derives the prefix of nested structs without a prefix tag from their field name, e.g. PRIMARY_ for a field named Primary.
+	// The name is converted with Naming, or UpperSnakeCase when Naming is nil.
+	AutoPrefix bool
+
+	// Naming derives the variable name of exported fields without a name in their env tag. Such fields are skipped when nil.
+	Naming NamingStrategy
+
+	// DurationUnit is the unit of time.Duration values given as plain integers.
+	DurationUnit time.Duration
+
+	// FuncMap is a map of custom parsing functions for specific types.
+	FuncMap map[reflect.Type]ParseFunc
+
+	// FormatMap is a map of custom formatting functions for specific types, the counterpart of FuncMap used by Marshal.
+	FormatMap map[reflect.Type]FormatFunc
+
+	// Source is where environment variables are read from. Defaults to the environment of the current process.
+	Source Source
+
+	// CollectErrors makes decoding continue past failing fields and return every failure in a MultiError.
+	CollectErrors bool
+
+	// AlwaysAllocateStructs allocates nil pointers to nested structs even when none of their variables are present.
+	AlwaysAllocateStructs bool
+
+	// FileFallback makes every field but maps fall back to reading the file named by its _FILE variable when its
+	// own variable is not set. Fields can opt in or out with the file and nofile options of their env tag.
+	FileFallback bool
+
+	// FileSuffix is appended to the name of a variable to get the name of the variable holding the name of its file.
+	FileSuffix string
+
+	// MaxFileSize is the maximum size in bytes of the files read through _FILE variables.
+	MaxFileSize int64
+
+	// RedactValues removes the offending values from the ParseError returned when a value cannot be parsed.
+	RedactValues bool
+}
+
+// Option configures the Options used by a Decoder.
+type Option func(*Options)
+
+func defaultOptions() Options {
+	return Options{
+		TagName:                "env",
+		DefaultTagName:         "defaultEnv",
+		Separator:              ",",
+		FuncMap:                nil,
+		SeparatorTagName:       "envSeparator",
+		PrefixTagName:          "envPrefix",
+		LayoutTagName:          "envLayout",
+		DescriptionTagName:     "envDesc",
+		ExampleTagName:         "envExample",
+		ValidateTagName:        "envValidate",
+		RequiredIfTagName:      "requiredIf",
+		RequiredWithTagName:    "requiredWith",
+		RequiredWithoutTagName: "requiredWithout",
+		ExcludedWithTagName:    "excludedWith",
+		DurationUnit:           time.Second,
+		FileSuffix:             "_FILE",
+		MaxFileSize:            1 << 20,
+		Source:                 OSSource{},
+	}
+}
+
+// withDefaults returns a copy of the options where every unset field is replaced by its default value.
+func (o Options) withDefaults() Options {
+	defaults := defaultOptions()
+	if o.TagName == "" {
+		o.TagName = defaults.TagName
+	}
+	if o.DefaultTagName == "" {
+		o.DefaultTagName = defaults.DefaultTagName
+	}
+	if o.SeparatorTagName == "" {
+		o.SeparatorTagName = defaults.SeparatorTagName
+	}
+	if o.PrefixTagName == "" {
+		o.PrefixTagName = defaults.PrefixTagName
+	}
+	if o.DescriptionTagName == "" {
+		o.DescriptionTagName = defaults.DescriptionTagName
+	}
+	if o.ExampleTagName == "" {
+		o.ExampleTagName = defaults.ExampleTagName
+	}
+	if o.LayoutTagName == "" {
+		o.LayoutTagName = defaults.LayoutTagName
+	}
+	if o.ValidateTagName == "" {
+		o.ValidateTagName = defaults.ValidateTagName
+	}
+	if o.RequiredIfTagName == "" {
+		o.RequiredIfTagName = defaults.RequiredIfTagName
+	}
+	if o.RequiredWithTagName == "" {
+		o.RequiredWithTagName = defaults.RequiredWithTagName
+	}
+	if o.RequiredWithoutTagName == "" {
+		o.RequiredWithoutTagName = defaults.RequiredWithoutTagName
+	}
+	if o.ExcludedWithTagName == "" {
+		o.ExcludedWithTagName = defaults.ExcludedWithTagName
+	}
+	if o.DurationUnit == 0 {
+		o.DurationUnit = defaults.DurationUnit
+	}
+	if o.FileSuffix == "" {
+		o.FileSuffix = defaults.FileSuffix
+	}
+	if o.MaxFileSize == 0 {
+		o.MaxFileSize = defaults.MaxFileSize
+	}
+	if o.Separator == "" {
+		o.Separator = defaults.Separator
+	}
+	if o.Source == nil {
+		o.Source = defaults.Source
+	}
+
+	return o
+}
+
+// WithTagName sets the tag name used to specify the environment variable name.
+func WithTagName(name string) Option {
+	return func(o *Options) {
+		o.TagName = name
+	}
+}
+
+// WithDefaultTagName sets the tag name used to specify the default value of a field.
+func WithDefaultTagName(name string) Option {
+	return func(o *Options) {
+		o.DefaultTagName = name
+	}
+}
+
+// WithSeparatorTagName sets the tag name used to specify the separator of a slice field.
+func WithSeparatorTagName(name string) Option {
+	return func(o *Options) {
+		o.SeparatorTagName = name
+	}
+}
+
+// WithPrefixTagName sets the tag name used to specify the prefix of the variables of a nested struct.
+func WithPrefixTagName(name string) Option {
+	return func(o *Options) {
+		o.PrefixTagName = name
+	}
+}
+
+// WithPrefix sets a prefix prepended to the name of every variable.
+func WithPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.Prefix = prefix
+	}
+}
+
+// WithAutoPrefix derives the prefix of nested structs without a prefix tag from their field name
+// converted to UPPER_SNAKE_CASE, followed by an underscore.
+func WithAutoPrefix() Option {
+	return func(o *Options) {
+		o.AutoPrefix = true
+	}
+}
+
+// WithNaming derives the variable name of exported fields without a name in their env tag using the given
+// strategy, e.g. UpperSnakeCase. Fields tagged `env:"-"` are still skipped.
+func WithNaming(naming NamingStrategy) Option {
+	return func(o *Options) {
+		o.Naming = naming
+	}
+}
+
+// WithDescriptionTagName sets the tag name used to document a field.
+func WithDescriptionTagName(name string) Option {
+	return func(o *Options) {
+		o.DescriptionTagName = name
+	}
+}
+
+// WithExampleTagName sets the tag name used to give an example value of a field.
+func WithExampleTagName(name string) Option {
+	return func(o *Options) {
+		o.ExampleTagName = name
+	}
+}
+
+// WithLayoutTagName sets the tag name used to specify the layout of time.Time fields.
+func WithLayoutTagName(name string) Option {
+	return func(o *Options) {
+		o.LayoutTagName = name
+	}
+}
+
+// WithValidateTagName sets the tag name used to specify the rules the values of a field must satisfy.
+func WithValidateTagName(name string) Option {
+	return func(o *Options) {
+		o.ValidateTagName = name
+	}
+}
+
+// WithConditionTagNames sets the tag names used to specify the conditional requirements of a field, in order
+// the requiredIf, requiredWith, requiredWithout and excludedWith tags.
+func WithConditionTagNames(requiredIf, requiredWith, requiredWithout, excludedWith string) Option {
+	return func(o *Options) {
+		o.RequiredIfTagName = requiredIf
+		o.RequiredWithTagName = requiredWith
+		o.RequiredWithoutTagName = requiredWithout
+		o.ExcludedWithTagName = excludedWith
+	}
+}
+
+// WithDurationUnit sets the unit of time.Duration values given as plain integers, time.Second by default.
+func WithDurationUnit(unit time.Duration) Option {
+	return func(o *Options) {
+		o.DurationUnit = unit
+	}
+}
+
+// WithSeparator sets the separator used when a slice field has no separator tag.
+func WithSeparator(separator string) Option {
+	return func(o *Options) {
+		o.Separator = separator
+	}
+}
+
+// WithFuncMap replaces the map of custom parsing functions.
+func WithFuncMap(funcMap map[reflect.Type]ParseFunc) Option {
+	return func(o *Options) {
+		o.FuncMap = funcMap
+	}
+}
+
+// WithParser registers a custom parsing function for the given type.
+func WithParser(typ reflect.Type, parseFunc ParseFunc) Option {
+	return func(o *Options) {
+		funcMap := make(map[reflect.Type]ParseFunc, len(o.FuncMap)+1)
+		for k, v := range o.FuncMap {
+			funcMap[k] = v
+		}
+		funcMap[typ] = parseFunc
+		o.FuncMap = funcMap
+	}
+}
+
+// WithSource sets the Source environment variables are read from.
+func WithSource(source Source) Option {
+	return func(o *Options) {
+		o.Source = source
+	}
+}
+
+// WithCollectErrors makes the Decoder decode every field and return all failures together in a MultiError,
+// instead of stopping at the first one.
+func WithCollectErrors() Option {
+	return func(o *Options) {
+		o.CollectErrors = true
+	}
+}
+
+// WithFileFallback makes every field but maps fall back to reading the file named by its _FILE variable, e.g.
+// DB_PASSWORD_FILE=/run/secrets/db_password, when its own variable is not set. Fields tagged with the nofile
+// option, e.g. `env:"HOST,nofile"`, are left out. Without this option only fields tagged with the file option
+// fall back to their file.
+func WithFileFallback() Option {
+	return func(o *Options) {
+		o.FileFallback = true
+	}
+}
+
+// WithFileSuffix sets the suffix of the variables holding the name of the file of a variable, _FILE by default.
+func WithFileSuffix(suffix string) Option {
+	return func(o *Options) {
+		o.FileSuffix = suffix
+	}
+}
+
+// WithMaxFileSize sets the maximum size in bytes of the files read through _FILE variables, 1 MiB by default.
+func WithMaxFileSize(size int64) Option {
+	return func(o *Options) {
+		o.MaxFileSize = size
+	}
+}
+
+// WithRedactValues removes the offending values from parse errors, so they can be logged safely.
+func WithRedactValues() Option {
+	return func(o *Options) {
+		o.RedactValues = true
+	}
+}
+
+// WithAlwaysAllocateStructs allocates nil pointers to nested structs even when none of their variables are present.
+// By default such pointers are left nil.
+func WithAlwaysAllocateStructs() Option {
+	return func(o *Options) {
+		o.AlwaysAllocateStructs = true
+	}
+}
+
+// WithFormatter registers a custom formatting function for the given type, used by Marshal.
+func WithFormatter(typ reflect.Type, formatFunc FormatFunc) Option {
+	return func(o *Options) {
+		formatMap := make(map[reflect.Type]FormatFunc, len(o.FormatMap)+1)
+		for k, v := range o.FormatMap {
+			formatMap[k] = v
+		}
+		formatMap[typ] = formatFunc
+		o.FormatMap = formatMap
+	}
+}
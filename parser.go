@@ -1,12 +1,41 @@
 package goenv
 
 import (
+	"encoding"
+	"encoding/json"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"strconv"
+	"time"
 )
 
 type ParseFunc func(string) (interface{}, error)
 
+// typeParseFunc parses values of a specific type, with access to the tag of the field and the decoding options.
+type typeParseFunc func(value string, tag reflect.StructTag, options Options) (interface{}, error)
+
+// defaultTypeParser holds the built-in parsers of types which cannot be parsed by their kind,
+// or whose unmarshaler does not fit environment variables. They take precedence over defaultParser.
+var defaultTypeParser = map[reflect.Type]typeParseFunc{
+	reflect.TypeOf(time.Duration(0)):      parseDuration,
+	reflect.TypeOf(time.Time{}):           parseTime,
+	reflect.TypeOf((*time.Location)(nil)): parseLocation,
+	reflect.TypeOf(url.URL{}):             parseURLValue,
+	reflect.TypeOf((*url.URL)(nil)):       parseURL,
+	reflect.TypeOf(net.IP{}):              parseIP,
+	reflect.TypeOf(net.IPNet{}):           parseIPNetValue,
+	reflect.TypeOf((*net.IPNet)(nil)):     parseIPNet,
+	reflect.TypeOf(net.HardwareAddr{}):    parseHardwareAddr,
+	reflect.TypeOf(netip.Addr{}):          parseAddr,
+	reflect.TypeOf(netip.Prefix{}):        parsePrefix,
+	reflect.TypeOf(netip.AddrPort{}):      parseAddrPort,
+	reflect.TypeOf(mail.Address{}):        parseMailAddressValue,
+	reflect.TypeOf((*mail.Address)(nil)):  parseMailAddress,
+}
+
 var (
 	defaultParser = map[reflect.Kind]ParseFunc{
 		reflect.Bool: func(v string) (interface{}, error) {
@@ -63,3 +92,80 @@ var (
 		},
 	}
 )
+
+// EnvUnmarshaler is implemented by types that can decode themselves from the value of an environment variable.
+// It takes precedence over encoding.TextUnmarshaler, encoding.BinaryUnmarshaler and json.Unmarshaler.
+type EnvUnmarshaler interface {
+	UnmarshalEnvValue(value string) error
+}
+
+var unmarshalerTypes = []reflect.Type{
+	reflect.TypeOf((*EnvUnmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+}
+
+// isUnmarshaler reports whether typ, or a pointer to typ, implements one of the supported unmarshaler interfaces.
+func isUnmarshaler(typ reflect.Type) bool {
+	for _, unmarshalerType := range unmarshalerTypes {
+		if typ.Implements(unmarshalerType) || reflect.PointerTo(typ).Implements(unmarshalerType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmarshalValue decodes a string into a new value of typ through the unmarshaler interface it implements.
+// Pointer types are allocated, so both value and pointer receivers are supported.
+func unmarshalValue(typ reflect.Type, value string) (reflect.Value, error) {
+	target, result := newUnmarshalTarget(typ)
+
+	var err error
+	switch unmarshaler := target.Interface().(type) {
+	case EnvUnmarshaler:
+		err = unmarshaler.UnmarshalEnvValue(value)
+	case encoding.TextUnmarshaler:
+		err = unmarshaler.UnmarshalText([]byte(value))
+	case encoding.BinaryUnmarshaler:
+		err = unmarshaler.UnmarshalBinary([]byte(value))
+	case json.Unmarshaler:
+		// values are decoded as JSON strings first, so string-backed types accept any value,
+		// and decoded as written when this fails, e.g. numbers or objects
+		quoted, _ := json.Marshal(value)
+		if err = unmarshaler.UnmarshalJSON(quoted); err != nil && json.Valid([]byte(value)) {
+			return unmarshalRawJSON(typ, value)
+		}
+	default:
+		return reflect.Value{}, NoParserFoundError{typ.String()}
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return result, nil
+}
+
+// newUnmarshalTarget allocates the value an unmarshaler decodes into, returning the pointer to call the unmarshaler
+// on and the resulting value of typ.
+func newUnmarshalTarget(typ reflect.Type) (target, result reflect.Value) {
+	if typ.Kind() == reflect.Ptr {
+		target = reflect.New(typ.Elem())
+		return target, target
+	}
+	target = reflect.New(typ)
+
+	return target, target.Elem()
+}
+
+// unmarshalRawJSON decodes a value written as JSON, such as a number or an object, into a new value of typ through
+// its json.Unmarshaler.
+func unmarshalRawJSON(typ reflect.Type, value string) (reflect.Value, error) {
+	target, result := newUnmarshalTarget(typ)
+	if err := target.Interface().(json.Unmarshaler).UnmarshalJSON([]byte(value)); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return result, nil
+}
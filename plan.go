@@ -0,0 +1,241 @@
+package goenv
+
+import (
+	"github.com/ilhamtubagus/condutil"
+	"reflect"
+)
+
+// structPlan is the decoding plan of a struct type, compiled once per type and Decoder: the fields to decode
+// with their tags read, their variable names resolved and their parsers looked up.
+type structPlan struct {
+	typ    reflect.Type
+	fields []fieldPlan
+
+	// path is the dotted path of the struct from the decoded struct, empty for the decoded struct itself.
+	path string
+}
+
+// fieldPlan is the decoding plan of a struct field.
+type fieldPlan struct {
+	index int
+
+	// path is the dotted path of the field from the decoded struct, e.g. Database.Port.
+	path string
+
+	// nested is the plan of a nested struct, set for struct fields and pointers to structs.
+	nested *structPlan
+
+	// recursive tells whether nested is the plan of one of the structs holding the field, as in linked lists.
+	// Such fields are not decoded, since their type is already being decoded.
+	recursive bool
+
+	// envName is the name of the variable of the field, including its prefix.
+	envName string
+
+	// fileEnvName is the name of the variable holding the name of the file read when envName is not set,
+	// empty when the field does not fall back to a file.
+	fileEnvName string
+
+	required     bool
+	defaultValue string
+	value        *valuePlan
+
+	// sensitive tells whether the values of the field are redacted from errors, for fields tagged sensitive
+	// or holding a Secret.
+	sensitive bool
+
+	// conditions are the conditional requirements of the field, checked once its struct is populated.
+	conditions []condition
+
+	// err is returned when decoding the field, for tags which cannot be compiled.
+	err error
+}
+
+type valueKind int
+
+const (
+	parsedKind valueKind = iota
+	pointerKind
+	sliceKind
+	mapKind
+)
+
+// valuePlan tells how a value of a type is set from a string.
+type valuePlan struct {
+	typ  reflect.Type
+	kind valueKind
+
+	// parse converts a string into a value of typ, for parsed values.
+	parse func(value string) (reflect.Value, error)
+
+	// elem is the plan of the value pointed to by pointers, and of the elements of slices and maps.
+	// Elements of slices and maps are always parsed values.
+	elem *valuePlan
+
+	// separator splits the value of slices, whose elements are pointers to elem when elemPtr is set.
+	separator string
+	elemPtr   bool
+
+	// rules are the rules of the validate tag checked by parsed values.
+	rules []validationRule
+}
+
+// compileStruct compiles the plan of a struct type decoded with the given options.
+func compileStruct(typ reflect.Type, path string, options Options) *structPlan {
+	return compileNestedStruct(typ, path, options, make(map[reflect.Type]*structPlan))
+}
+
+// compileNestedStruct compiles the plan of a struct type held by the structs whose plans are under construction
+// in ancestors. Fields holding one of these types, as in recursive types like linked lists, reuse its plan
+// instead of compiling it again, so the compilation ends.
+func compileNestedStruct(typ reflect.Type, path string, options Options, ancestors map[reflect.Type]*structPlan) *structPlan {
+	plan := &structPlan{typ: typ, path: path}
+	ancestors[typ] = plan
+	defer delete(ancestors, typ)
+
+	for i := 0; i < typ.NumField(); i++ {
+		if field, ok := compileField(typ.Field(i), joinPath(path, typ.Field(i).Name), options, ancestors); ok {
+			field.index = i
+			plan.fields = append(plan.fields, field)
+		}
+	}
+	compileConditions(plan, options)
+
+	return plan
+}
+
+// compileField compiles the plan of a field of a struct held by the structs in ancestors, see compileNestedStruct.
+// It returns false when the field is not decoded at all, because it is ignored or has no variable name.
+func compileField(fieldType reflect.StructField, path string, options Options, ancestors map[reflect.Type]*structPlan) (fieldPlan, bool) {
+	field := fieldPlan{path: path}
+
+	// skip fields explicitly ignored with `env:"-"`
+	if fieldType.Tag.Get(options.TagName) == ignoredTag {
+		return field, false
+	}
+
+	// nested structs are decoded field by field, unless they can be parsed from a single value
+	var nestedType reflect.Type
+	if typ := fieldType.Type; typ.Kind() == reflect.Struct && !hasParser(typ, options) {
+		nestedType = typ
+	} else if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct && !hasParser(typ, options) &&
+		!hasParser(typ.Elem(), options) {
+		nestedType = typ.Elem()
+	}
+	if nestedType != nil {
+		if plan, ok := ancestors[nestedType]; ok {
+			field.nested, field.recursive = plan, true
+		} else {
+			field.nested = compileNestedStruct(nestedType, path, nestedOptions(fieldType, options), ancestors)
+		}
+		return field, true
+	}
+
+	envName, tagOpts := parseTag(fieldType.Tag.Get(options.TagName))
+	// derive the name of exported fields without one when a naming strategy is set
+	if condutil.IsZeroValue(envName) && options.Naming != nil && fieldType.IsExported() {
+		envName = options.Naming(fieldType.Name)
+	}
+	// skip parsing when env tag is empty
+	if condutil.IsZeroValue(envName) {
+		return field, false
+	}
+
+	field.envName = options.Prefix + envName
+	field.required = tagOpts.Contains(requiredOption)
+	field.sensitive = tagOpts.Contains(sensitiveOption) || holdsSecret(fieldType.Type)
+	field.defaultValue = fieldType.Tag.Get(options.DefaultTagName)
+	if fieldType.Type.Kind() != reflect.Map && !tagOpts.Contains(noFileOption) &&
+		(options.FileFallback || tagOpts.Contains(fileOption)) {
+		field.fileEnvName = field.envName + options.FileSuffix
+	}
+	field.value = compileValue(fieldType.Type, fieldType.Tag, options)
+
+	// validation rules apply to the parsed values: the value pointed to, or the elements of slices and maps
+	leaf := field.value
+	for leaf.elem != nil {
+		leaf = leaf.elem
+	}
+	leaf.rules, field.err = compileRules(fieldType.Tag.Get(options.ValidateTagName), leaf.typ, leaf.parse, path)
+
+	return field, true
+}
+
+// compileValue compiles the plan of a value of typ. The tag is the tag of the field holding the value.
+func compileValue(typ reflect.Type, tag reflect.StructTag, options Options) *valuePlan {
+	plan := &valuePlan{typ: typ}
+
+	// a parser for the slice, map or pointer type itself takes precedence over parsing their elements
+	if !hasParser(typ, options) {
+		switch typ.Kind() {
+		case reflect.Ptr:
+			plan.kind = pointerKind
+			plan.elem = compileValue(typ.Elem(), tag, options)
+			return plan
+		case reflect.Slice:
+			plan.kind = sliceKind
+			plan.separator = tag.Get(options.SeparatorTagName)
+			if condutil.IsZeroValue(plan.separator) {
+				plan.separator = options.Separator
+			}
+			elemType := typ.Elem()
+			if elemType.Kind() == reflect.Ptr && !hasParser(elemType, options) {
+				plan.elemPtr = true
+				elemType = elemType.Elem()
+			}
+			plan.elem = &valuePlan{typ: elemType, parse: compileParser(elemType, tag, options)}
+			return plan
+		case reflect.Map:
+			plan.kind = mapKind
+			plan.elem = &valuePlan{typ: typ.Elem(), parse: compileParser(typ.Elem(), tag, options)}
+			return plan
+		}
+	}
+
+	plan.parse = compileParser(typ, tag, options)
+	return plan
+}
+
+// compileParser returns the function converting a string into a value of the given type, using in order the
+// custom parser registered for the type, the parser of the value of a Secret, the built-in parser of the type,
+// the unmarshaler interface implemented by the type or the default parser of its kind. The tag is the tag of the field being parsed.
+func compileParser(typ reflect.Type, tag reflect.StructTag, options Options) func(string) (reflect.Value, error) {
+	if parseFunc, ok := options.FuncMap[typ]; ok {
+		return wrapParseFunc(parseFunc, typ)
+	}
+	if isSecret(typ) {
+		return compileSecretParser(typ, tag, options)
+	}
+	if typeParseFunc, ok := defaultTypeParser[typ]; ok {
+		return func(value string) (reflect.Value, error) {
+			parsedValue, err := typeParseFunc(value, tag, options)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return convertValue(parsedValue, typ)
+		}
+	}
+	if isUnmarshaler(typ) {
+		return func(value string) (reflect.Value, error) {
+			return unmarshalValue(typ, value)
+		}
+	}
+	if parseFunc, ok := defaultParser[typ.Kind()]; ok {
+		return wrapParseFunc(parseFunc, typ)
+	}
+
+	return func(string) (reflect.Value, error) {
+		return reflect.Value{}, NoParserFoundError{typ.String()}
+	}
+}
+
+// wrapParseFunc converts the values returned by a ParseFunc into the given type.
+func wrapParseFunc(parseFunc ParseFunc, typ reflect.Type) func(string) (reflect.Value, error) {
+	return func(value string) (reflect.Value, error) {
+		parsedValue, err := parseFunc(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return convertValue(parsedValue, typ)
+	}
+}
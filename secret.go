@@ -0,0 +1,122 @@
+package goenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+)
+
+// Secret holds a sensitive value, such as a password or a token, which is redacted wherever it could be printed:
+// by fmt, encoding/json, encoding and log/slog. The value is only available through Value, and is written by
+// Marshal so that it round-trips through Unmarshal.
+//
+// Secret fields are decoded like fields of type T, which must have a parser, e.g. Secret[string] or
+// Secret[*url.URL]. Their values are redacted from decoding errors, as with the sensitive option.
+//
+//	type Config struct {
+//	    Password goenv.Secret[string] `env:"DB_PASSWORD,required"`
+//	}
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the value held by the secret.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String returns a redacted placeholder instead of the value.
+func (s Secret[T]) String() string {
+	return redactedValue
+}
+
+// GoString returns a redacted placeholder instead of the value, used by the %#v verb.
+func (s Secret[T]) GoString() string {
+	return fmt.Sprintf("goenv.Secret[%s]{%s}", reflect.TypeOf((*T)(nil)).Elem(), redactedValue)
+}
+
+// Format writes a redacted placeholder instead of the value, whatever the verb and flags.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, s.GoString())
+		return
+	}
+	io.WriteString(f, redactedValue)
+}
+
+// MarshalJSON encodes a redacted placeholder instead of the value.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedValue)
+}
+
+// MarshalText encodes a redacted placeholder instead of the value. Marshal does not use it, see Secret.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redactedValue), nil
+}
+
+// LogValue logs a redacted placeholder instead of the value.
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redactedValue)
+}
+
+func (s *Secret[T]) setSecretValue(value reflect.Value) {
+	s.value = value.Interface().(T)
+}
+
+// secretSetter is implemented by pointers to Secret types, so they can be told apart from other structs.
+type secretSetter interface {
+	setSecretValue(value reflect.Value)
+}
+
+var secretSetterType = reflect.TypeOf((*secretSetter)(nil)).Elem()
+
+// isSecret reports whether typ is a Secret type.
+func isSecret(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && reflect.PointerTo(typ).Implements(secretSetterType)
+}
+
+// holdsSecret reports whether values of typ hold a Secret, directly or as the value pointed to, or the elements of
+// slices and maps.
+func holdsSecret(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+
+	return isSecret(typ)
+}
+
+// compileSecretParser returns the function converting a string into a Secret of type typ, parsing the value it
+// holds with the parser of its type.
+func compileSecretParser(typ reflect.Type, tag reflect.StructTag, options Options) func(string) (reflect.Value, error) {
+	parse := compileParser(typ.Field(0).Type, tag, options)
+
+	return func(value string) (reflect.Value, error) {
+		parsedValue, err := parse(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		secret := reflect.New(typ)
+		secret.Interface().(secretSetter).setSecretValue(parsedValue)
+
+		return secret.Elem(), nil
+	}
+}
+
+// formatSecret formats the value held by a Secret with the formatter of its type, rather than the redacted
+// placeholder of its MarshalText method, which would not be read back by Unmarshal.
+func formatSecret(secret reflect.Value, tag reflect.StructTag, options Options) (string, error) {
+	value := secret.MethodByName("Value").Call(nil)[0]
+	// secrets are never decoded into nil pointers, so these are written as empty values
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return "", nil
+	}
+
+	return formatValue(value, tag, options)
+}
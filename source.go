@@ -0,0 +1,191 @@
+package goenv
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Lookuper retrieves the value of a single environment variable.
+type Lookuper interface {
+	// Lookup returns the value of the variable named by key and whether it is present.
+	Lookup(key string) (string, bool)
+}
+
+// Source is a Lookuper that can also enumerate the names of all of its variables.
+// It is used to populate map fields, whose entries are discovered by prefix.
+type Source interface {
+	Lookuper
+
+	// Keys returns the names of all variables in the source.
+	Keys() []string
+}
+
+// OSSource reads variables from the environment of the current process.
+type OSSource struct{}
+
+func (OSSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (OSSource) Keys() []string {
+	envVars := os.Environ()
+	keys := make([]string, 0, len(envVars))
+	for _, env := range envVars {
+		key, _, found := strings.Cut(env, "=")
+		// skip malformed entries and the hidden per-drive variables Windows stores as "=C:=C:\"
+		if !found || key == "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// MapSource reads variables from a map of names to values.
+type MapSource map[string]string
+
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m MapSource) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// NewPairsSource creates a Source from a list of "KEY=VALUE" pairs, in the format returned by os.Environ.
+// When a key appears more than once the last value wins.
+// It returns InvalidEnvironmentVariableError if a pair does not contain "=" or has an empty key.
+func NewPairsSource(pairs []string) (MapSource, error) {
+	source := make(MapSource, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, InvalidEnvironmentVariableError
+		}
+		source[key] = value
+	}
+
+	return source, nil
+}
+
+// Precedence decides which layer of a LayeredSource wins when several layers define the same variable.
+type Precedence int
+
+const (
+	// FirstWins gives precedence to the first layer defining a variable.
+	FirstWins Precedence = iota
+
+	// LastWins gives precedence to the last layer defining a variable, so later layers override earlier ones.
+	LastWins
+)
+
+// LayeredSource chains several sources into one. A variable is looked up in every layer
+// according to the precedence, and the key set is the union of the keys of all layers.
+type LayeredSource struct {
+	layers     []Source
+	precedence Precedence
+}
+
+// NewLayeredSource creates a LayeredSource from the given layers.
+//
+// For example, to read defaults from .env, overrides from .env.local, then the process environment:
+//
+//	base, _ := NewDotenvSource(".env")
+//	local, _ := NewDotenvSource(".env.local")
+//	source := NewLayeredSource(LastWins, base, local, OSSource{})
+func NewLayeredSource(precedence Precedence, layers ...Source) *LayeredSource {
+	return &LayeredSource{
+		layers:     layers,
+		precedence: precedence,
+	}
+}
+
+func (s *LayeredSource) Lookup(key string) (string, bool) {
+	for i := range s.layers {
+		layer := s.layers[i]
+		if s.precedence == LastWins {
+			layer = s.layers[len(s.layers)-1-i]
+		}
+		if value, ok := layer.Lookup(key); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *LayeredSource) Keys() []string {
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, layer := range s.layers {
+		for _, key := range layer.Keys() {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// snapshotSource reads a Source once per decode: the process environment is copied into a map, and the keys
+// of a source are listed at most once, however many map fields are decoded.
+type snapshotSource struct {
+	Source
+	keys   []string
+	listed bool
+}
+
+func newSnapshotSource(source Source) *snapshotSource {
+	if _, ok := source.(OSSource); ok {
+		envVars := os.Environ()
+		environ := make(MapSource, len(envVars))
+		for _, env := range envVars {
+			key, value, found := strings.Cut(env, "=")
+			// like os.LookupEnv, the first of duplicated variables wins
+			if _, duplicated := environ[key]; !found || key == "" || duplicated {
+				continue
+			}
+			environ[key] = value
+		}
+		source = environ
+	}
+
+	return &snapshotSource{Source: source}
+}
+
+func (s *snapshotSource) Keys() []string {
+	if !s.listed {
+		s.keys = s.Source.Keys()
+		s.listed = true
+	}
+
+	return s.keys
+}
+
+// presenceSource wraps a Source and records whether any variable has been found in it.
+type presenceSource struct {
+	Source
+	found bool
+}
+
+func (s *presenceSource) Lookup(key string) (string, bool) {
+	value, ok := s.Source.Lookup(key)
+	if ok {
+		s.found = true
+	}
+
+	return value, ok
+}
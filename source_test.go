@@ -0,0 +1,112 @@
+package goenv
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestOSSource(t *testing.T) {
+	_ = os.Setenv("OS_SOURCE_FIELD", "value")
+
+	value, ok := OSSource{}.Lookup("OS_SOURCE_FIELD")
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+	assert.Contains(t, OSSource{}.Keys(), "OS_SOURCE_FIELD")
+}
+
+func TestMapSource(t *testing.T) {
+	source := MapSource{"B": "2", "A": "1"}
+
+	value, ok := source.Lookup("A")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	_, ok = source.Lookup("C")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"A", "B"}, source.Keys())
+}
+
+func TestNewPairsSource(t *testing.T) {
+	t.Run("Valid pairs", func(t *testing.T) {
+		source, err := NewPairsSource([]string{"A=1", "B=x=y", "A=3", "EMPTY="})
+
+		assert.Nil(t, err)
+		assert.Equal(t, MapSource{"A": "3", "B": "x=y", "EMPTY": ""}, source)
+	})
+
+	t.Run("Invalid pair", func(t *testing.T) {
+		_, err := NewPairsSource([]string{"A=1", "B"})
+
+		assert.ErrorIs(t, err, InvalidEnvironmentVariableError)
+	})
+}
+
+func TestUnmarshal_Source(t *testing.T) {
+	type Config struct {
+		Host    string            `env:"SOURCE_HOST"`
+		Port    int               `env:"SOURCE_PORT" defaultEnv:"8080"`
+		Labels  map[string]string `env:"SOURCE_LABELS"`
+		Servers []string          `env:"SOURCE_SERVERS"`
+	}
+	source := MapSource{
+		"SOURCE_HOST":           "localhost",
+		"SOURCE_LABELS_APP":     "goenv",
+		"SOURCE_LABELS_TEAM_ID": "core",
+		"SOURCE_SERVERS":        "a,b",
+	}
+
+	actualStruct := &Config{}
+	err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+	assert.Nil(t, err)
+	assert.Equal(t, Config{
+		Host:    "localhost",
+		Port:    8080,
+		Labels:  map[string]string{"app": "goenv", "teamId": "core"},
+		Servers: []string{"a", "b"},
+	}, *actualStruct)
+}
+
+func TestLayeredSource(t *testing.T) {
+	base := MapSource{"HOST": "base", "PORT": "80", "LABELS_APP": "goenv"}
+	local := MapSource{"HOST": "local", "LABELS_TEAM": "core"}
+	cli := MapSource{"PORT": "8080"}
+
+	t.Run("Last wins", func(t *testing.T) {
+		source := NewLayeredSource(LastWins, base, local, cli)
+
+		host, _ := source.Lookup("HOST")
+		port, _ := source.Lookup("PORT")
+		_, ok := source.Lookup("MISSING")
+		assert.Equal(t, "local", host)
+		assert.Equal(t, "8080", port)
+		assert.False(t, ok)
+		assert.Equal(t, []string{"HOST", "LABELS_APP", "LABELS_TEAM", "PORT"}, source.Keys())
+	})
+
+	t.Run("First wins", func(t *testing.T) {
+		source := NewLayeredSource(FirstWins, base, local, cli)
+
+		host, _ := source.Lookup("HOST")
+		port, _ := source.Lookup("PORT")
+		assert.Equal(t, "base", host)
+		assert.Equal(t, "80", port)
+	})
+
+	t.Run("Map field sees keys from every layer", func(t *testing.T) {
+		type Config struct {
+			Host   string            `env:"HOST"`
+			Labels map[string]string `env:"LABELS"`
+		}
+
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(NewLayeredSource(LastWins, base, local, cli))).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Config{
+			Host:   "local",
+			Labels: map[string]string{"app": "goenv", "team": "core"},
+		}, *actualStruct)
+	})
+}
@@ -0,0 +1,39 @@
+package goenv
+
+import "strings"
+
+const (
+	// ignoredTag is the env tag of fields which must not be decoded.
+	ignoredTag = "-"
+
+	// requiredOption marks a field whose environment variable must be set.
+	requiredOption = "required"
+
+	// sensitiveOption marks a field whose values must not appear in errors, e.g. `env:"DB_PASSWORD,sensitive"`.
+	sensitiveOption = "sensitive"
+)
+
+// tagOptions is the list of comma separated options following the variable name in an env tag,
+// e.g. `env:"DB_HOST,required"`.
+type tagOptions []string
+
+// parseTag splits an env tag into the variable name and its options.
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, found := strings.Cut(tag, ",")
+	if !found {
+		return name, nil
+	}
+
+	return name, strings.Split(opts, ",")
+}
+
+// Contains reports whether the options contain the given option.
+func (o tagOptions) Contains(option string) bool {
+	for _, opt := range o {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package goenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// unixLayout parses times given as seconds since the Unix epoch.
+	unixLayout = "unix"
+
+	// unixMilliLayout parses times given as milliseconds since the Unix epoch.
+	unixMilliLayout = "unixmilli"
+)
+
+// namedLayouts are the layouts of the time package that can be referred to by name in the layout tag.
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// parseDuration parses a duration in Go syntax such as "1m30s", or a plain integer counted in options.DurationUnit.
+func parseDuration(value string, _ reflect.StructTag, options Options) (interface{}, error) {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(i) * options.DurationUnit, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// parseTime parses a time with the layout given in the layout tag of the field, RFC 3339 by default.
+// The layout is either a Go layout, the name of a layout of the time package (e.g. DateOnly),
+// "unix" for seconds or "unixmilli" for milliseconds since the Unix epoch.
+func parseTime(value string, tag reflect.StructTag, options Options) (interface{}, error) {
+	layout := tag.Get(options.LayoutTagName)
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+
+	switch strings.ToLower(layout) {
+	case unixLayout:
+		sec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unix time: %w", err)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	case unixMilliLayout:
+		msec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unix time: %w", err)
+		}
+		return time.UnixMilli(msec).UTC(), nil
+	}
+
+	return time.Parse(layout, value)
+}
+
+// parseLocation loads a location from its IANA name, e.g. Europe/Paris. "UTC" and "Local" are also accepted.
+func parseLocation(value string, _ reflect.StructTag, _ Options) (interface{}, error) {
+	return time.LoadLocation(value)
+}
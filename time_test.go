@@ -0,0 +1,81 @@
+package goenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal_Time(t *testing.T) {
+	type Config struct {
+		Timeout   time.Duration            `env:"TIMEOUT"`
+		Intervals []time.Duration          `env:"INTERVALS"`
+		Limits    map[string]time.Duration `env:"LIMITS"`
+		StartAt   time.Time                `env:"START_AT"`
+		Birthday  time.Time                `env:"BIRTHDAY" envLayout:"DateOnly"`
+		Custom    time.Time                `env:"CUSTOM" envLayout:"02/01/2006"`
+		CreatedAt time.Time                `env:"CREATED_AT" envLayout:"unix"`
+		UpdatedAt time.Time                `env:"UPDATED_AT" envLayout:"unixmilli"`
+		Zone      *time.Location           `env:"ZONE"`
+		Zones     []*time.Location         `env:"ZONES"`
+	}
+	source := MapSource{
+		"TIMEOUT":     "1m30s",
+		"INTERVALS":   "10,500ms",
+		"LIMITS_READ": "2s",
+		"START_AT":    "2024-05-01T10:00:00Z",
+		"BIRTHDAY":    "1990-12-31",
+		"CUSTOM":      "25/12/2023",
+		"CREATED_AT":  "1700000000",
+		"UPDATED_AT":  "1700000000123",
+		"ZONE":        "Europe/Paris",
+		"ZONES":       "UTC,Asia/Tokyo",
+	}
+
+	actualStruct := &Config{}
+	err := NewDecoder(WithSource(source)).Decode(actualStruct)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, actualStruct.Timeout)
+	assert.Equal(t, []time.Duration{10 * time.Second, 500 * time.Millisecond}, actualStruct.Intervals)
+	assert.Equal(t, map[string]time.Duration{"read": 2 * time.Second}, actualStruct.Limits)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), actualStruct.StartAt)
+	assert.Equal(t, time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC), actualStruct.Birthday)
+	assert.Equal(t, time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC), actualStruct.Custom)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), actualStruct.CreatedAt)
+	assert.Equal(t, time.UnixMilli(1700000000123).UTC(), actualStruct.UpdatedAt)
+	assert.Equal(t, "Europe/Paris", actualStruct.Zone.String())
+	assert.Len(t, actualStruct.Zones, 2)
+	assert.Equal(t, "Asia/Tokyo", actualStruct.Zones[1].String())
+
+	t.Run("Duration unit", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(MapSource{"TIMEOUT": "250"}), WithDurationUnit(time.Millisecond)).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 250*time.Millisecond, actualStruct.Timeout)
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			source MapSource
+			field  string
+		}{
+			{"Duration", MapSource{"TIMEOUT": "soon"}, "Timeout"},
+			{"Time", MapSource{"START_AT": "yesterday"}, "StartAt"},
+			{"Unix time", MapSource{"CREATED_AT": "now"}, "CreatedAt"},
+			{"Location", MapSource{"ZONE": "Mars/Olympus"}, "Zone"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := NewDecoder(WithSource(tt.source)).Decode(&Config{})
+
+				var parseErr ParseError
+				assert.True(t, errors.As(err, &parseErr))
+				assert.Equal(t, tt.field, parseErr.Field)
+			})
+		}
+	})
+}
@@ -16,3 +16,12 @@ func snakeToCamelCase(input string) string {
 	}
 	return strings.Join(words, "")
 }
+
+// joinPath appends a field name to the dotted path of its parent struct.
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
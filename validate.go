@@ -0,0 +1,211 @@
+package goenv
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator is implemented by structs checking rules which span several fields, such as a TLS certificate
+// requiring a key. Once the fields of a struct are decoded, Validate is called on it, so nested structs are
+// validated before the structs holding them. The returned error is wrapped in a ParseError holding the path
+// of the struct.
+type Validator interface {
+	Validate() error
+}
+
+// validationRule is a compiled rule of a validate tag, e.g. `envValidate:"min=1,max=65535"`.
+type validationRule struct {
+	// text is the rule as written in the tag, e.g. min=1.
+	text string
+
+	// check reports whether a parsed value satisfies the rule. The raw argument is the string it was parsed from.
+	check func(value reflect.Value, raw string) bool
+}
+
+// compileRules compiles the rules of a validate tag, checking the values of typ parsed by parse.
+// Rules are separated by commas, except regex which must come last: its pattern extends to the end
+// of the tag, so it may contain commas. The rules of a Secret are checked against the value it holds.
+func compileRules(tag string, typ reflect.Type, parse func(string) (reflect.Value, error), path string) ([]validationRule, error) {
+	secret := isSecret(typ)
+	if secret {
+		parseSecret := parse
+		parse = func(value string) (reflect.Value, error) {
+			parsedValue, err := parseSecret(value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return parsedValue.Field(0), nil
+		}
+		typ = typ.Field(0).Type
+	}
+
+	var rules []validationRule
+	for tag != "" {
+		var text string
+		tag = strings.TrimLeft(tag, " ")
+		if strings.HasPrefix(tag, "regex=") {
+			text, tag = tag, ""
+		} else {
+			text, tag, _ = strings.Cut(tag, ",")
+			text = strings.TrimSpace(text)
+		}
+		if text == "" {
+			continue
+		}
+
+		check, err := compileRule(text, typ, parse)
+		if err != nil {
+			return nil, InvalidRuleError{Field: path, Rule: text, Err: err}
+		}
+		if secret {
+			checkValue := check
+			check = func(value reflect.Value, raw string) bool {
+				return checkValue(value.Field(0), raw)
+			}
+		}
+		rules = append(rules, validationRule{text: text, check: check})
+	}
+
+	return rules, nil
+}
+
+func compileRule(text string, typ reflect.Type, parse func(string) (reflect.Value, error)) (func(reflect.Value, string) bool, error) {
+	name, arg, hasArg := strings.Cut(text, "=")
+	if name == "notEmpty" {
+		if hasArg {
+			return nil, fmt.Errorf("rule %s takes no argument", name)
+		}
+		return func(_ reflect.Value, raw string) bool {
+			return raw != ""
+		}, nil
+	}
+	if !hasArg {
+		return nil, fmt.Errorf("rule %s requires an argument", name)
+	}
+
+	switch name {
+	case "min", "max":
+		compare, err := compareTo(arg, typ, parse)
+		if err != nil {
+			return nil, err
+		}
+		if name == "min" {
+			return func(value reflect.Value, _ string) bool { return compare(value) >= 0 }, nil
+		}
+		return func(value reflect.Value, _ string) bool { return compare(value) <= 0 }, nil
+	case "len":
+		if typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("not supported by type %s", typ)
+		}
+		compare, err := compareTo(arg, typ, parse)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value, _ string) bool { return compare(value) == 0 }, nil
+	case "oneof":
+		allowed := strings.Fields(arg)
+		if len(allowed) == 0 {
+			return nil, fmt.Errorf("rule %s requires at least one value", name)
+		}
+		return func(_ reflect.Value, raw string) bool {
+			for _, a := range allowed {
+				if raw == a {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ reflect.Value, raw string) bool {
+			return re.MatchString(raw)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown rule %s", name)
+}
+
+// compareTo returns a function comparing values of typ to the bound given by arg. The length in characters
+// of strings is compared to an integer, and numbers are compared to a number parsed like the values of typ,
+// so the bounds of a time.Duration can be written as 1s or 5m.
+func compareTo(arg string, typ reflect.Type, parse func(string) (reflect.Value, error)) (func(reflect.Value) int, error) {
+	switch typ.Kind() {
+	case reflect.String:
+		bound, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) int {
+			return cmp.Compare(utf8.RuneCountInString(value.String()), bound)
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		bound, err := parse(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) int {
+			return compareNumbers(value, bound)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("not supported by type %s", typ)
+}
+
+func compareNumbers(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	default:
+		return cmp.Compare(a.Float(), b.Float())
+	}
+}
+
+// validate checks a parsed value against the rules of its plan, returning a ValidationError for the first
+// rule it does not satisfy.
+func (p *valuePlan) validate(value reflect.Value, path, envName, raw string, options Options) error {
+	for _, rule := range p.rules {
+		if rule.check(value, raw) {
+			continue
+		}
+
+		validationErr := ValidationError{Field: path, Var: envName, Rule: rule.text, Value: raw}
+		if options.RedactValues {
+			return validationErr.redact()
+		}
+		return validationErr
+	}
+
+	return nil
+}
+
+// validateStruct calls the Validate method of a decoded struct, through a pointer when possible so both value
+// and pointer receivers are supported.
+func validateStruct(value reflect.Value, plan *structPlan) error {
+	if value.CanAddr() {
+		value = value.Addr()
+	}
+	if !value.CanInterface() {
+		return nil
+	}
+	validator, ok := value.Interface().(Validator)
+	if !ok {
+		return nil
+	}
+
+	if err := validator.Validate(); err != nil {
+		return ParseError{Field: plan.path, Type: plan.typ, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package goenv
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal_Validate(t *testing.T) {
+	type Config struct {
+		Port    int               `env:"PORT" envValidate:"min=1,max=65535"`
+		Level   string            `env:"LEVEL" envValidate:"oneof=debug info warn"`
+		Name    string            `env:"NAME" envValidate:"notEmpty,regex=^[a-z]{1,8}$"`
+		Code    *string           `env:"CODE" envValidate:"len=3"`
+		Ratio   float64           `env:"RATIO" envValidate:"min=0.5"`
+		Timeout time.Duration     `env:"TIMEOUT" defaultEnv:"5s" envValidate:"min=1s,max=1m"`
+		Hosts   []string          `env:"HOSTS" envValidate:"notEmpty"`
+		Weights map[string]uint   `env:"WEIGHTS" envValidate:"max=10"`
+		Labels  map[string]string `env:"LABELS" envValidate:"min=2"`
+	}
+	validSource := func() MapSource {
+		return MapSource{
+			"PORT":        "8080",
+			"LEVEL":       "info",
+			"NAME":        "app",
+			"CODE":        "abc",
+			"RATIO":       "0.5",
+			"HOSTS":       "a,b",
+			"WEIGHTS_API": "10",
+			"LABELS_TEAM": "core",
+		}
+	}
+
+	t.Run("Valid values", func(t *testing.T) {
+		actualStruct := &Config{}
+		err := NewDecoder(WithSource(validSource())).Decode(actualStruct)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, actualStruct.Port)
+		assert.Equal(t, "abc", *actualStruct.Code)
+		assert.Equal(t, 5*time.Second, actualStruct.Timeout)
+		assert.Equal(t, map[string]uint{"api": 10}, actualStruct.Weights)
+	})
+
+	tests := []struct {
+		name     string
+		variable string
+		value    string
+		expected ValidationError
+	}{
+		{"Below min", "PORT", "0", ValidationError{Field: "Port", Var: "PORT", Rule: "min=1", Value: "0"}},
+		{"Above max", "PORT", "70000", ValidationError{Field: "Port", Var: "PORT", Rule: "max=65535", Value: "70000"}},
+		{"Not one of", "LEVEL", "trace", ValidationError{Field: "Level", Var: "LEVEL", Rule: "oneof=debug info warn", Value: "trace"}},
+		{"Empty", "NAME", "", ValidationError{Field: "Name", Var: "NAME", Rule: "notEmpty", Value: ""}},
+		{"Not matching", "NAME", "App", ValidationError{Field: "Name", Var: "NAME", Rule: "regex=^[a-z]{1,8}$", Value: "App"}},
+		{"Wrong length", "CODE", "abcd", ValidationError{Field: "Code", Var: "CODE", Rule: "len=3", Value: "abcd"}},
+		{"Float below min", "RATIO", "0.25", ValidationError{Field: "Ratio", Var: "RATIO", Rule: "min=0.5", Value: "0.25"}},
+		{"Duration above max", "TIMEOUT", "2m", ValidationError{Field: "Timeout", Var: "TIMEOUT", Rule: "max=1m", Value: "2m"}},
+		{"Empty slice element", "HOSTS", "a,,b", ValidationError{Field: "Hosts[1]", Var: "HOSTS", Rule: "notEmpty", Value: ""}},
+		{"Map value above max", "WEIGHTS_API", "11", ValidationError{Field: "Weights[api]", Var: "WEIGHTS_API", Rule: "max=10", Value: "11"}},
+		{"Short map value", "LABELS_TEAM", "x", ValidationError{Field: "Labels[team]", Var: "LABELS_TEAM", Rule: "min=2", Value: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := validSource()
+			source[tt.variable] = tt.value
+
+			err := NewDecoder(WithSource(source)).Decode(&Config{})
+
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+
+	t.Run("Invalid default value", func(t *testing.T) {
+		type Config struct {
+			Timeout time.Duration `env:"TIMEOUT" defaultEnv:"0s" envValidate:"min=1s"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{})).Decode(&Config{})
+
+		assert.Equal(t, ValidationError{Field: "Timeout", Var: "TIMEOUT", Rule: "min=1s", Value: "0s"}, err)
+	})
+
+	t.Run("Unset variable", func(t *testing.T) {
+		type Config struct {
+			Name string `env:"NAME" envValidate:"notEmpty"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{})).Decode(&Config{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Redacted value", func(t *testing.T) {
+		source := validSource()
+		source["PORT"] = "0"
+
+		err := NewDecoder(WithSource(source), WithRedactValues()).Decode(&Config{})
+
+		assert.Equal(t, ValidationError{Field: "Port", Var: "PORT", Rule: "min=1", Redacted: true}, err)
+		assert.Equal(t, "value of PORT does not satisfy min=1 (field Port)", err.Error())
+	})
+
+	t.Run("Collected errors", func(t *testing.T) {
+		source := validSource()
+		source["PORT"] = "0"
+		source["LEVEL"] = "trace"
+
+		err := NewDecoder(WithSource(source), WithCollectErrors()).Decode(&Config{})
+
+		var multiErr MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 2)
+		assert.IsType(t, ValidationError{}, multiErr.Errors[0])
+		assert.IsType(t, ValidationError{}, multiErr.Errors[1])
+	})
+
+	t.Run("Custom tag name", func(t *testing.T) {
+		type Config struct {
+			Port int `env:"PORT" check:"max=10"`
+		}
+
+		err := NewDecoder(WithSource(MapSource{"PORT": "11"}), WithValidateTagName("check")).Decode(&Config{})
+
+		assert.Equal(t, ValidationError{Field: "Port", Var: "PORT", Rule: "max=10", Value: "11"}, err)
+	})
+}
+
+func TestUnmarshal_InvalidRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		target interface{}
+		rule   string
+	}{
+		{"Unknown rule", &struct {
+			Port int `env:"PORT" envValidate:"positive"`
+		}{}, "positive"},
+		{"Missing argument", &struct {
+			Port int `env:"PORT" envValidate:"min"`
+		}{}, "min"},
+		{"Invalid bound", &struct {
+			Port int `env:"PORT" envValidate:"min=one"`
+		}{}, "min=one"},
+		{"Unsupported type", &struct {
+			Debug bool `env:"DEBUG" envValidate:"max=1"`
+		}{}, "max=1"},
+		{"Length of number", &struct {
+			Port int `env:"PORT" envValidate:"len=4"`
+		}{}, "len=4"},
+		{"Invalid regex", &struct {
+			Name string `env:"NAME" envValidate:"regex=[a-"`
+		}{}, "regex=[a-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDecoder(WithSource(MapSource{})).Decode(tt.target)
+
+			var ruleErr InvalidRuleError
+			assert.True(t, errors.As(err, &ruleErr))
+			assert.Equal(t, tt.rule, ruleErr.Rule)
+		})
+	}
+}
+
+type validatedTLS struct {
+	Cert string `env:"CERT"`
+	Key  string `env:"KEY"`
+}
+
+func (t validatedTLS) Validate() error {
+	if (t.Cert == "") != (t.Key == "") {
+		return errors.New("cert and key must be set together")
+	}
+
+	return nil
+}
+
+type validatedConfig struct {
+	MinConns int           `env:"MIN_CONNS"`
+	MaxConns int           `env:"MAX_CONNS"`
+	TLS      validatedTLS  `envPrefix:"TLS_"`
+	Admin    *validatedTLS `envPrefix:"ADMIN_"`
+
+	calls *[]string
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.calls != nil {
+		*c.calls = append(*c.calls, "config")
+	}
+	if c.MaxConns < c.MinConns {
+		return errors.New("max conns must not be lower than min conns")
+	}
+
+	return nil
+}
+
+func TestUnmarshal_Validator(t *testing.T) {
+	t.Run("Valid struct", func(t *testing.T) {
+		source := MapSource{"MIN_CONNS": "1", "MAX_CONNS": "2", "TLS_CERT": "cert", "TLS_KEY": "key"}
+
+		err := NewDecoder(WithSource(source)).Decode(&validatedConfig{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Invalid struct", func(t *testing.T) {
+		source := MapSource{"MIN_CONNS": "2", "MAX_CONNS": "1"}
+
+		err := NewDecoder(WithSource(source)).Decode(&validatedConfig{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "", parseErr.Field)
+		assert.Equal(t, "invalid goenv.validatedConfig: max conns must not be lower than min conns", err.Error())
+	})
+
+	t.Run("Invalid nested struct", func(t *testing.T) {
+		calls := []string{}
+		source := MapSource{"MIN_CONNS": "2", "MAX_CONNS": "1", "TLS_CERT": "cert"}
+
+		err := NewDecoder(WithSource(source)).Decode(&validatedConfig{calls: &calls})
+
+		assert.Equal(t, ParseError{
+			Field: "TLS",
+			Type:  reflect.TypeOf(validatedTLS{}),
+			Err:   errors.New("cert and key must be set together"),
+		}, err)
+		assert.Equal(t, "invalid goenv.validatedTLS (field TLS): cert and key must be set together", err.Error())
+		assert.Empty(t, calls)
+	})
+
+	t.Run("Invalid pointer to struct", func(t *testing.T) {
+		source := MapSource{"ADMIN_KEY": "key"}
+
+		err := NewDecoder(WithSource(source)).Decode(&validatedConfig{})
+
+		var parseErr ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, "Admin", parseErr.Field)
+	})
+
+	t.Run("Unset pointer to struct", func(t *testing.T) {
+		err := NewDecoder(WithSource(MapSource{})).Decode(&validatedConfig{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Collected errors", func(t *testing.T) {
+		calls := []string{}
+		source := MapSource{"MIN_CONNS": "2", "MAX_CONNS": "1", "TLS_CERT": "cert", "ADMIN_KEY": "key"}
+
+		err := NewDecoder(WithSource(source), WithCollectErrors()).Decode(&validatedConfig{calls: &calls})
+
+		var multiErr MultiError
+		assert.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Errors, 2)
+		assert.Empty(t, calls)
+	})
+}
@@ -0,0 +1,112 @@
+package goenv
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteDotenv writes variables as a dotenv file which can be read back with ParseDotenv.
+// Values are double-quoted and escaped when needed, so multi-line values are supported.
+func WriteDotenv(w io.Writer, vars map[string]string) error {
+	return writeVars(w, vars, func(key, value string) (string, error) {
+		return key + "=" + quoteDotenv(value), nil
+	})
+}
+
+// WriteDockerEnvFile writes variables in the format of `docker run --env-file`, where values are taken
+// literally and cannot be quoted. It fails with MultilineValueError when a value contains a line break.
+func WriteDockerEnvFile(w io.Writer, vars map[string]string) error {
+	return writeVars(w, vars, func(key, value string) (string, error) {
+		if strings.ContainsAny(value, "\n\r") {
+			return "", fmt.Errorf("%w: %s", MultilineValueError, key)
+		}
+		return key + "=" + value, nil
+	})
+}
+
+// WriteSystemdEnvFile writes variables in the format of the systemd EnvironmentFile directive.
+// Values are double-quoted and escaped when needed, and multi-line values are kept inside the quotes.
+func WriteSystemdEnvFile(w io.Writer, vars map[string]string) error {
+	return writeVars(w, vars, func(key, value string) (string, error) {
+		if isPlainValue(value) {
+			return key + "=" + value, nil
+		}
+		return key + `="` + escapeSystemd.Replace(value) + `"`, nil
+	})
+}
+
+// WriteExportScript writes variables as a POSIX shell script of export statements, meant to be sourced.
+// Values are single-quoted when needed.
+func WriteExportScript(w io.Writer, vars map[string]string) error {
+	return writeVars(w, vars, func(key, value string) (string, error) {
+		if isPlainValue(value) {
+			return "export " + key + "=" + value, nil
+		}
+		return "export " + key + "='" + strings.ReplaceAll(value, "'", `'\''`) + "'", nil
+	})
+}
+
+// writeVars writes one line per variable, sorted by name, as formatted by formatLine.
+func writeVars(w io.Writer, vars map[string]string, formatLine func(key, value string) (string, error)) error {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		line, err := formatLine(key, vars[key])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	escapeDotenv = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"`", "\\`",
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+
+	escapeSystemd = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"`", "\\`",
+	)
+)
+
+// quoteDotenv double-quotes and escapes a value unless it can be written as is.
+func quoteDotenv(value string) string {
+	if isPlainValue(value) {
+		return value
+	}
+
+	return `"` + escapeDotenv.Replace(value) + `"`
+}
+
+// isPlainValue reports whether a value can be written unquoted in every supported format.
+func isPlainValue(value string) bool {
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("_-.,:/@%+=", r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}